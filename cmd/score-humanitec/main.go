@@ -18,6 +18,9 @@ func main() {
 
 	if err := command.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		if hint := command.ErrorHint(err); hint != "" {
+			fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+		}
+		os.Exit(command.ExitCode(err))
 	}
 }
@@ -0,0 +1,77 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	api "github.com/score-spec/score-humanitec/internal/humanitec_go/client"
+)
+
+// addTransportFlags registers the HTTP transport flags shared by all the commands calling the Humanitec API.
+func addTransportFlags(flags *pflag.FlagSet) {
+	flags.DurationVar(&requestTimeout, "request-timeout", requestTimeoutDefault, "Timeout for a single Humanitec API request (0 means no timeout)")
+	flags.StringVar(&caFile, "ca-file", "", "PEM bundle with additional certificate authorities to trust")
+	flags.StringVar(&clientCertFile, "client-cert", "", "PEM client certificate for mutual TLS (requires --client-key)")
+	flags.StringVar(&clientKeyFile, "client-key", "", "PEM client private key for mutual TLS (requires --client-cert)")
+	flags.BoolVar(&insecureSkipVerify, "insecure-skip-verify", false, "Disable the Humanitec API server certificate verification (insecure)")
+	flags.StringVar(&proxyURL, "proxy", "", "HTTP proxy URL (defaults to the HTTPS_PROXY and NO_PROXY environment variables)")
+	flags.BoolVar(&trace, "trace", false, "Log all Humanitec API requests and responses as JSON lines (written to STDERR)")
+	flags.StringVar(&traceFile, "trace-file", "", "Append the Humanitec API trace to the file instead of STDERR (implies --trace)")
+	flags.StringArrayVar(&traceSecretPatterns, "trace-secret-pattern", nil, "Additional regular expression for variable names and file paths to redact in the trace (can be repeated)")
+}
+
+// newApiClient creates the Humanitec API client configured by the command line flags.
+func newApiClient() (api.Client, error) {
+	httpClient, err := api.NewHTTPClient(api.TransportOptions{
+		Timeout:            requestTimeout,
+		CAFile:             caFile,
+		ClientCertFile:     clientCertFile,
+		ClientKeyFile:      clientKeyFile,
+		InsecureSkipVerify: insecureSkipVerify,
+		ProxyURL:           proxyURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var policy = api.DefaultRetryPolicy()
+	policy.MaxRetries = retryMax
+	policy.MaxDuration = retryTimeout
+	var opts = []api.ClientOption{api.WithRetryPolicy(policy)}
+
+	if trace || traceFile != "" {
+		tracer, err := newTracer()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, api.WithTracer(tracer))
+	}
+
+	return api.NewClient(apiUrl, apiToken, httpClient, opts...)
+}
+
+// newTracer creates the Humanitec API tracer writing into the --trace-file, or STDERR.
+// The file is opened for the lifetime of the process.
+func newTracer() (*api.Tracer, error) {
+	var out io.Writer = os.Stderr
+	if traceFile != "" {
+		file, err := os.OpenFile(traceFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("opening trace file: %w", err)
+		}
+		out = file
+	}
+
+	var patterns = append(append([]string{}, api.DefaultSecretPatterns...), traceSecretPatterns...)
+	return api.NewTracer(out, patterns)
+}
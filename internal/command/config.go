@@ -0,0 +1,99 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/score-spec/score-humanitec/internal/config"
+)
+
+func init() {
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configUseCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage configuration contexts",
+	Long: `Configuration contexts provide defaults for the Humanitec API access flags.
+
+Contexts are stored in '$XDG_CONFIG_HOME/score-humanitec/config.yaml' (or the file set by SCORE_HUMANITEC_CONFIG):
+
+  current-context: staging
+  contexts:
+    staging:
+      org: my-org
+      app: my-app
+      env: staging
+      api-url: https://api.humanitec.io
+      ca-file: /etc/ssl/corporate-ca.pem
+
+Values are resolved in the following order: command line flags, environment variables, and the selected context.
+Supported environment variables are HUMANITEC_TOKEN, HUMANITEC_ORG, HUMANITEC_APP, HUMANITEC_ENV, HUMANITEC_API_URL,
+HUMANITEC_UI_URL, HUMANITEC_REQUEST_TIMEOUT, HUMANITEC_CA_FILE, HUMANITEC_CLIENT_CERT, HUMANITEC_CLIENT_KEY and
+HUMANITEC_PROXY.`,
+}
+
+var configListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List the configuration contexts",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := config.DefaultPath()
+		if err != nil {
+			return err
+		}
+		cfg, err := config.Load(path)
+		if err != nil {
+			return err
+		}
+
+		var out = cmd.OutOrStdout()
+		for _, name := range cfg.ContextNames() {
+			var ctx = cfg.Contexts[name]
+			var marker = " "
+			if name == cfg.CurrentContext {
+				marker = "*"
+			}
+			fmt.Fprintf(out, "%s %s\torg=%s app=%s env=%s api-url=%s\n", marker, name, ctx.Org, ctx.App, ctx.Env, ctx.ApiUrl)
+		}
+		return nil
+	},
+}
+
+var configUseCmd = &cobra.Command{
+	Use:          "use CONTEXT",
+	Short:        "Select the current configuration context",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := config.DefaultPath()
+		if err != nil {
+			return err
+		}
+		cfg, err := config.Load(path)
+		if err != nil {
+			return err
+		}
+
+		if _, err := cfg.Context(args[0]); err != nil {
+			return err
+		}
+		cfg.CurrentContext = args[0]
+		if err := cfg.Save(path); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Switched to context '%s'\n", args[0])
+		return nil
+	},
+}
@@ -7,6 +7,8 @@ The Apache Software Foundation (http://www.apache.org/).
 */
 package command
 
+import "time"
+
 const (
 	scoreFileDefault      = "./score.yaml"
 	overridesFileDefault  = "./overrides.score.yaml"
@@ -14,10 +16,26 @@ const (
 	apiUrlDefault         = "https://api.humanitec.io"
 	uiUrlDefault          = "https://app.humanitec.io"
 	messageDefault        = "Auto-deployment (SCORE)"
+
+	waitTimeoutDefault  = 30 * time.Minute
+	waitIntervalDefault = 5 * time.Second
+
+	retryMaxDefault     = 10
+	retryTimeoutDefault = 5 * time.Minute
+
+	requestTimeoutDefault = 1 * time.Minute
+
+	envTypeDefault = "development"
+
+	formatTable = "table"
+	formatJSON  = "json"
+
+	modeAdd    = "add"
+	modeUpdate = "update"
 )
 
 var (
-	scoreFile         string
+	scoreFiles        []string
 	overridesFile     string
 	extensionsFile    string
 	uiUrl             string
@@ -27,6 +45,8 @@ var (
 	appID             string
 	envID             string
 	workloadSourceURL string
+	resourceTypesFile string
+	contextName       string
 
 	overrideParams []string
 	currentImage   string
@@ -36,6 +56,51 @@ var (
 	deltaID        string
 	deploy         bool
 	retry          bool
+	wait           bool
+	waitTimeout    time.Duration
 	skipValidation bool
+	strict         bool
+	validateOutput bool
 	verbose        bool
+	noColor        bool
+
+	skipResourceValidation bool
+
+	retryMax     int
+	retryTimeout time.Duration
+
+	// waitInterval is the deployment status polling interval (overridden in tests)
+	waitInterval = waitIntervalDefault
+
+	requestTimeout     time.Duration
+	caFile             string
+	clientCertFile     string
+	clientKeyFile      string
+	insecureSkipVerify bool
+	proxyURL           string
+
+	trace               bool
+	traceFile           string
+	traceSecretPatterns []string
+
+	baseEnvID string
+	envName   string
+	envType   string
+	ensureEnv bool
+
+	rollbackTo string
+
+	archiveOnSuccess bool
+	archived         bool
+	outputFormat     string
+
+	moduleID        string
+	importScoreFile string
+	overwrite       bool
+
+	deltaMode string
+	prune     bool
+	dryRun    bool
+
+	forceAdopt bool
 )
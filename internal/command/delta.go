@@ -8,13 +8,12 @@ The Apache Software Foundation (http://www.apache.org/).
 package command
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
-	"net/http"
 	"os"
-	"path/filepath"
 	"regexp"
 
 	"github.com/score-spec/score-humanitec/internal/humanitec"
@@ -24,13 +23,18 @@ import (
 )
 
 func init() {
-	deltaCmd.Flags().StringVarP(&scoreFile, "file", "f", scoreFileDefault, "Source SCORE file")
+	deltaCmd.Flags().StringArrayVarP(&scoreFiles, "file", "f", []string{scoreFileDefault}, "Source SCORE file (can be repeated or use a glob pattern)")
 	deltaCmd.Flags().StringVar(&overridesFile, "overrides", overridesFileDefault, "Overrides file")
 	deltaCmd.Flags().StringVar(&extensionsFile, "extensions", extensionsFileDefault, "Extensions file")
 	deltaCmd.Flags().StringVar(&workloadSourceURL, "workload-source-url", "", "URL of file that is managing the humanitec workload")
 	deltaCmd.Flags().StringVar(&uiUrl, "ui-url", uiUrlDefault, "Humanitec UI")
 	deltaCmd.Flags().StringVar(&apiUrl, "api-url", apiUrlDefault, "Humanitec API endpoint")
+	deltaCmd.Flags().StringVar(&resourceTypesFile, "resource-types-file", "", "Cached JSON list of the organization resource types to validate resources against (skips the API call)")
 	deltaCmd.Flags().StringVar(&deltaID, "delta", "", "The ID of an existing delta in Humanitec into which to merge the generated delta")
+	deltaCmd.Flags().BoolVar(&forceAdopt, "force-adopt", false, "Replace deployed modules even if they are not managed by score-humanitec, or from another workload source")
+	deltaCmd.Flags().BoolVar(&prune, "prune", false, "Remove the workloads and shared resources deployed from SCORE files that are not declared anymore (shared resources are only removed if deployed with --prune)")
+	deltaCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print what --prune would remove, without creating the delta")
+	deltaCmd.Flags().StringVar(&deltaMode, "mode", modeAdd, "How deployed modules are changed: 'add' replaces them, 'update' patches the fields generated from the SCORE files only")
 
 	deltaCmd.Flags().StringVar(&apiToken, "token", "", "Humanitec API authentication token")
 	deltaCmd.MarkFlagRequired("token")
@@ -45,10 +49,22 @@ func init() {
 	deltaCmd.Flags().StringVarP(&currentImage, "image", "i", ".", "Image to use for the current image, signified by \".\"")
 	deltaCmd.Flags().StringVarP(&message, "message", "m", messageDefault, "Message")
 
+	deltaCmd.Flags().BoolVar(&ensureEnv, "ensure-env", false, "Create the environment from the base environment if it does not exist (requires --base-env)")
+	deltaCmd.Flags().StringVar(&baseEnvID, "base-env", "", "Base environment ID to clone when the environment is created by --ensure-env")
 	deltaCmd.Flags().BoolVar(&deploy, "deploy", false, "Trigger a new delta deployment at the end")
-	deltaCmd.Flags().BoolVar(&retry, "retry", false, "Retry deployments when a deployment is currently in progress")
+	deltaCmd.Flags().BoolVar(&retry, "retry", false, "Retry deployments when a deployment is currently in progress (limited by --retry-max and --retry-timeout)")
+	deltaCmd.Flags().IntVar(&retryMax, "retry-max", retryMaxDefault, "Maximum number of retries for transient Humanitec API errors (0 disables retries)")
+	deltaCmd.Flags().DurationVar(&retryTimeout, "retry-timeout", retryTimeoutDefault, "Maximum time to spend retrying a Humanitec API call")
+	addTransportFlags(deltaCmd.Flags())
+	deltaCmd.Flags().BoolVar(&wait, "wait", false, "Wait for the triggered deployment to complete (requires --deploy)")
+	deltaCmd.Flags().BoolVar(&archiveOnSuccess, "archive-on-success", false, "Archive the delta once the triggered deployment succeeds (requires --deploy, implies --wait)")
+	deltaCmd.Flags().DurationVar(&waitTimeout, "timeout", waitTimeoutDefault, "Maximum time to wait for the deployment to complete")
+	deltaCmd.Flags().BoolVar(&validateOutput, "validate-output", false, "Validate the generated deployment delta against the embedded JSON schema")
+	deltaCmd.Flags().BoolVar(&strict, "strict", false, "Fail if any '${...}' reference can not be resolved")
+	deltaCmd.Flags().BoolVar(&skipResourceValidation, "skip-resource-validation", false, "Disables validation of resources against the organization resource types")
 	deltaCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "DEPRECATED: Disables Score file schema validation.")
 	deltaCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable diagnostic messages (written to STDERR)")
+	addOutputFlags(deltaCmd.Flags())
 
 	rootCmd.AddCommand(deltaCmd)
 }
@@ -59,19 +75,45 @@ var deltaCmd = &cobra.Command{
 	Long: `This command will translate the SCORE file into a Humanitec deployment delta and submit it to the Humanitec
 environment specified by the --org, --app, and --env flags. If the --delta flag is provided, the generated delta will
 be merged with the specified existing delta. The --deploy flag allows the deployment of the delta to be triggered.
+The --wait flag makes the command wait until the triggered deployment completes, and fail if the deployment fails.
+The --archive-on-success flag archives the delta once the deployment succeeds.
+The --ensure-env flag creates the environment from the last deployment of the --base-env environment if it does not
+exist yet, e.g. for preview environments.
+The --mode update flag patches the modules already deployed in the environment instead of replacing them: the values
+added by other tools are kept, as well as the module fields listed in the 'score.humanitec.io/managed-elsewhere'
+annotation (comma-separated JSON pointers, e.g. '/spec/replicas'). The fields generated from the SCORE files are
+recorded in the 'humanitec.io/owned-paths' annotation, and removed by the next update once the SCORE files do not
+generate them anymore. Nothing is removed from the modules deployed without this annotation, e.g. with --mode add.
+The deployed modules are only replaced if they are annotated as managed by score-humanitec, and from the same
+--workload-source-url if they were deployed with one. The --force-adopt flag skips this check, to take ownership of
+the modules.
+The --prune flag removes the modules deployed from SCORE files that are not part of the source SCORE files anymore,
+as well as the shared resources they declared, unless another module still uses them. All the workloads of the
+environment must therefore be provided together. The removals are listed on STDERR, and the --dry-run flag stops
+there without creating the delta.
+
+The command outputs a versioned result in the --format format (json, yaml or compact), to STDOUT or the --output file:
+
+  apiVersion  score-humanitec/v1
+  kind        DeltaResult
+  delta       the created or updated deployment delta
+  url         the deployment delta URL in the Humanitec UI
+  deployment  the triggered deployment 'id' and 'status' (with --deploy only)
+  pruned      the removed 'modules' and 'shared' resources (with --prune only)
+  warnings    the warnings reported while converting the SCORE files
+
+The result is written once the delta is created or updated, even if the deployment or the archiving fails,
+so that the delta and the deployment status can be inspected.
 `,
 	RunE: delta,
 }
 
 func delta(cmd *cobra.Command, args []string) error {
-	if !verbose {
-		log.SetOutput(io.Discard)
-	}
+	setupLogging()
 
-	// Load SCORE spec and extensions
+	// Load SCORE specs and extensions
 	//
-	baseDir := filepath.Dir(scoreFile)
-	spec, ext, err := loadSpec(scoreFile, overridesFile, extensionsFile, skipValidation)
+	workloads, err := loadWorkloads(scoreFiles, overridesFile, extensionsFile, skipValidation)
 	if err != nil {
 		return err
 	}
@@ -80,20 +122,102 @@ func delta(cmd *cobra.Command, args []string) error {
 	if err := validateIDs(); err != nil {
 		return err
 	}
+	if wait && !deploy {
+		return fmt.Errorf("the --wait flag requires the --deploy flag")
+	}
+	if archiveOnSuccess && !deploy {
+		return fmt.Errorf("the --archive-on-success flag requires the --deploy flag")
+	}
+	if ensureEnv && baseEnvID == "" {
+		return fmt.Errorf("the --ensure-env flag requires the --base-env flag")
+	}
+	if err := validateResultFormat(); err != nil {
+		return err
+	}
+	if err := validateDeltaMode(); err != nil {
+		return err
+	}
+	if dryRun && !prune {
+		return fmt.Errorf("the --dry-run flag requires the --prune flag")
+	}
+
+	client, err := newApiClient()
+	if err != nil {
+		return err
+	}
+
+	// Validate resources against the organization resource types (optional)
+	//
+	if !skipResourceValidation {
+		if err := validateResources(cmd.Context(), client, workloads); err != nil {
+			return err
+		}
+	}
 
 	// Prepare a new deployment
 	//
 	log.Print("Preparing a new deployment...\n")
-	delta, err := humanitec.ConvertSpec(message, envID, baseDir, workloadSourceURL, spec, ext)
+	delta, warnings, err := humanitec.ConvertSpecs(message, envID, workloadSourceURL, workloads, strict)
 	if err != nil {
 		return fmt.Errorf("preparing new deployment: %w", err)
 	}
+	logWarnings(warnings)
+	if validateOutput {
+		log.Print("Validating deployment delta...\n")
+		if err := humanitec.ValidateDelta(delta); err != nil {
+			return fmt.Errorf("validating deployment delta: %w", err)
+		}
+	}
 
-	client, err := api.NewClient(apiUrl, apiToken, http.DefaultClient)
+	// Create the environment if missing (optional)
+	//
+	if ensureEnv && dryRun {
+		log.Printf("Skipping the creation of environment '%s' (dry run)...\n", envID)
+	} else if ensureEnv {
+		if err := ensureEnvironment(cmd.Context(), client); err != nil {
+			return err
+		}
+	}
+
+	// Check the deployed modules ownership, patch them and prune the removed ones (optional)
+	//
+	log.Printf("Fetching the deployment set for environment '%s'...\n", envID)
+	set, err := api.GetDeployedSet(cmd.Context(), client, orgID, appID, envID)
 	if err != nil {
+		// The environment is not created in dry runs, it would be empty
+		var created = ensureEnv && dryRun
+		if !api.IsNotFound(err) || ((deltaMode == modeUpdate || prune) && !created) {
+			return err
+		}
+		// Nothing is deployed in a missing environment, the delta creation reports the error
+		set = &ht.Set{}
+	}
+	if forceAdopt {
+		log.Print("Skipping the modules ownership check...\n")
+	} else if err := humanitec.CheckOwnership(delta, set); err != nil {
 		return err
 	}
 
+	var pruned *humanitec.PruneSummary
+	if prune {
+		humanitec.AnnotateSharedResources(delta, workloads)
+	}
+	if deltaMode == modeUpdate {
+		if delta, err = humanitec.UpdateModules(delta, set); err != nil {
+			return fmt.Errorf("preparing module updates: %w", err)
+		}
+	}
+	if prune {
+		if delta, pruned, err = humanitec.PruneDelta(delta, set, workloadNames(workloads)); err != nil {
+			return fmt.Errorf("preparing removals: %w", err)
+		}
+		logWarnings(pruned.Warnings)
+		printPruneSummary(cmd.ErrOrStderr(), pruned, dryRun)
+		if dryRun {
+			return nil
+		}
+	}
+
 	var res *ht.DeploymentDelta
 	if deltaID == "" {
 		log.Print("Creating a new deployment delta...\n")
@@ -108,39 +232,183 @@ func delta(cmd *cobra.Command, args []string) error {
 	}
 	res.Metadata.Url = fmt.Sprintf("%s/orgs/%s/apps/%s/envs/%s/draft/%s", uiUrl, orgID, appID, delta.Metadata.EnvID, res.ID)
 
-	// Output resulting deployment delta
+	var result = deltaResult{
+		ApiVersion: deltaResultApiVersion,
+		Kind:       deltaResultKind,
+		Delta:      res,
+		Url:        res.Metadata.Url,
+		Pruned:     pruned,
+		Warnings:   []string{},
+	}
+	if pruned != nil {
+		warnings = append(warnings, pruned.Warnings...)
+	}
+	for _, warning := range warnings {
+		result.Warnings = append(result.Warnings, warning.String())
+	}
+
+	// Trigger the deployment (optional)
+	//
+	if deploy {
+		err = deployDelta(cmd.Context(), cmd.ErrOrStderr(), client, res, &result)
+	}
+
+	// Output the result, even if the deployment failed, for the delta and the deployment status to be available
 	//
-	tmp, err := json.MarshalIndent(res, "", "  ")
+	if werr := writeResult(cmd.OutOrStdout(), &result); werr != nil {
+		if err != nil {
+			log.Printf("Failed to write the output: %v\n", werr)
+			return err
+		}
+		return werr
+	}
+	return err
+}
+
+// deployDelta starts a deployment of the delta, then waits for it to complete and archives the delta (optional).
+// The deployment details are recorded in the result as soon as they are known.
+func deployDelta(ctx context.Context, out io.Writer, client api.Client, res *ht.DeploymentDelta, result *deltaResult) error {
+	log.Printf("Starting a new deployment for delta '%s'...\n", res.ID)
+	deployment, err := client.StartDeployment(ctx, orgID, appID, envID, retry, &ht.StartDeploymentRequest{
+		DeltaID: res.ID,
+		Comment: message,
+	})
 	if err != nil {
 		return err
 	}
-	os.Stdout.Write(tmp)
+	result.Deployment = &deploymentResult{ID: deployment.ID, Status: deployment.Status}
 
-	// Trigger the deployment (optional)
+	// Wait for the deployment to complete (optional)
 	//
-	if deploy {
-		log.Printf("Starting a new deployment for delta '%s'...\n", res.ID)
-		_, err := client.StartDeployment(cmd.Context(), orgID, appID, envID, retry, &ht.StartDeploymentRequest{
-			DeltaID: res.ID,
-			Comment: message,
-		})
+	if wait || archiveOnSuccess {
+		completed, err := waitForDeployment(ctx, out, client, deployment.ID)
+		if completed != nil {
+			result.Deployment.Status = completed.Status
+		}
 		if err != nil {
 			return err
 		}
 	}
 
+	// Archive the deployed delta (optional)
+	//
+	if archiveOnSuccess {
+		log.Printf("Archiving deployment delta '%s'...\n", res.ID)
+		if err := client.ArchiveDelta(ctx, orgID, appID, res.ID); err != nil {
+			return fmt.Errorf("archiving deployment delta '%s': %w", res.ID, err)
+		}
+		res.Metadata.Archived = true
+	}
 	return nil
 }
 
+// waitForDeployment blocks until the deployment completes, reporting status changes to the out writer (STDERR).
+// Returns the completed deployment, and an error if the deployment fails or does not complete in time.
+func waitForDeployment(ctx context.Context, out io.Writer, client api.Client, deploymentID string) (*ht.Deployment, error) {
+	if waitTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, waitTimeout)
+		defer cancel()
+	}
+
+	res, err := api.WaitForDeployment(ctx, client, orgID, appID, envID, deploymentID, waitInterval, func(d *ht.Deployment) {
+		fmt.Fprintf(out, "Deployment '%s' is %s\n", d.ID, d.Status)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Status != ht.DeploymentStatusSucceeded {
+		return res, fmt.Errorf("deployment '%s' %s", res.ID, res.Status)
+	}
+
+	return res, nil
+}
+
+// validateResources checks workloads resources against the organization resource types.
+// The resource types are read from the --resource-types-file if provided, or fetched with the client otherwise.
+func validateResources(ctx context.Context, client api.Client, workloads []humanitec.WorkloadSource) error {
+	var resTypes []ht.ResourceType
+	if resourceTypesFile != "" {
+		log.Printf("Reading resource types from '%s'...\n", resourceTypesFile)
+		raw, err := os.ReadFile(resourceTypesFile)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(raw, &resTypes); err != nil {
+			return fmt.Errorf("parsing resource types file '%s': %w", resourceTypesFile, err)
+		}
+	} else {
+		log.Print("Fetching resource types...\n")
+		var err error
+		if resTypes, err = client.ListResourceTypes(ctx, orgID); err != nil {
+			return err
+		}
+	}
+
+	log.Print("Validating resources...\n")
+	return humanitec.ValidateResources(workloads, resTypes)
+}
+
+// workloadNames returns the names of the workloads.
+func workloadNames(workloads []humanitec.WorkloadSource) []string {
+	var names = make([]string, 0, len(workloads))
+	for _, w := range workloads {
+		var name, _ = w.Spec.Metadata["name"].(string)
+		names = append(names, name)
+	}
+	return names
+}
+
+// printPruneSummary lists the modules and shared resources removed by --prune.
+func printPruneSummary(out io.Writer, summary *humanitec.PruneSummary, dryRun bool) {
+	if summary.IsEmpty() {
+		fmt.Fprintln(out, "Nothing to prune.")
+		return
+	}
+	if dryRun {
+		fmt.Fprintln(out, "The following would be removed (dry run):")
+	} else {
+		fmt.Fprintln(out, "The following will be removed:")
+	}
+	for _, name := range summary.Modules {
+		fmt.Fprintf(out, "  - module '%s'\n", name)
+	}
+	for _, name := range summary.Shared {
+		fmt.Fprintf(out, "  - shared resource '%s'\n", name)
+	}
+}
+
+// validateDeltaMode checks the --mode flag value.
+func validateDeltaMode() error {
+	switch deltaMode {
+	case modeAdd, modeUpdate:
+		return nil
+	default:
+		return fmt.Errorf("unsupported mode '%s', expected '%s' or '%s'", deltaMode, modeAdd, modeUpdate)
+	}
+}
+
 var validID = regexp.MustCompile(`^[a-z0-9](?:-?[a-z0-9]+)+$`)
 
 func validateIDs() error {
+	return checkIDs(true)
+}
+
+// validateAppIDs checks the IDs like validateIDs, for the commands where the environment is optional.
+func validateAppIDs() error {
+	return checkIDs(envID != "")
+}
+
+func checkIDs(withEnv bool) error {
 	ids := []struct {
 		name string
 		id   string
 	}{
 		{"organization", orgID}, {"application", appID}, {"environment", envID},
 	}
+	if !withEnv {
+		ids = ids[:2]
+	}
 
 	for _, e := range ids {
 		if !validID.MatchString(e.id) {
@@ -0,0 +1,449 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v3"
+
+	api "github.com/score-spec/score-humanitec/internal/humanitec_go/client"
+	ht "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+	"github.com/score-spec/score-humanitec/pkg/humanitec/fake"
+)
+
+const (
+	testOrgID = "test-org"
+	testAppID = "test-app"
+	testEnvID = "development"
+)
+
+// newFakeServer starts a fake Humanitec API with a 'development' environment and the 'postgres' resource type.
+func newFakeServer(t *testing.T) *fake.Server {
+	var srv = fake.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddEnvironment(testOrgID, testAppID, ht.Environment{ID: testEnvID, Type: "development"})
+	srv.SetResourceTypes(testOrgID, []ht.ResourceType{
+		{
+			Type: "postgres",
+			OutputsSchema: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"values": map[string]interface{}{
+						"properties": map[string]interface{}{"host": map[string]interface{}{"type": "string"}},
+					},
+				},
+			},
+		},
+	})
+	return srv
+}
+
+// deltaArgs returns the common 'delta' command arguments to use the fake server.
+func deltaArgs(srv *fake.Server, scoreFile string, extra ...string) []string {
+	return append([]string{
+		"delta", "-f", scoreFile,
+		"--api-url", srv.URL, "--token", srv.Token,
+		"--org", testOrgID, "--app", testAppID, "--env", testEnvID,
+	}, extra...)
+}
+
+func TestDelta(t *testing.T) {
+	var srv = newFakeServer(t)
+	var scoreFile = writeFile(t, t.TempDir(), "score.yaml", testScoreFile)
+
+	stdout, _, err := executeCommand(t, deltaArgs(srv, scoreFile)...)
+	assert.NoError(t, err)
+
+	var result deltaResult
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.Equal(t, "score-humanitec/v1", result.ApiVersion)
+	assert.Equal(t, "DeltaResult", result.Kind)
+	assert.Nil(t, result.Deployment)
+	assert.Equal(t, []string{}, result.Warnings)
+	var res = result.Delta
+	assert.Equal(t, "https://app.humanitec.io/orgs/test-org/apps/test-app/envs/development/draft/"+res.ID, result.Url)
+	assert.Equal(t, result.Url, res.Metadata.Url)
+
+	var deltas = srv.Deltas(testOrgID, testAppID)
+	assert.Len(t, deltas, 1)
+	assert.Equal(t, res.ID, deltas[0].ID)
+	assert.Contains(t, deltas[0].Modules.Add, "web")
+	assert.Empty(t, srv.Deployments(testOrgID, testAppID, testEnvID))
+
+	// Update the existing delta in place
+	_, _, err = executeCommand(t, deltaArgs(srv, scoreFile, "--delta", res.ID, "-m", "Updated")...)
+	assert.NoError(t, err)
+	assert.Len(t, srv.Deltas(testOrgID, testAppID), 1)
+}
+
+func TestDelta_deploy(t *testing.T) {
+	var srv = newFakeServer(t)
+	srv.DeploymentPolls = 2
+	waitInterval = time.Millisecond
+	t.Cleanup(func() { waitInterval = waitIntervalDefault })
+	var scoreFile = writeFile(t, t.TempDir(), "score.yaml", testScoreFile)
+
+	stdout, stderr, err := executeCommand(t, deltaArgs(srv, scoreFile, "--deploy", "--wait", "--archive-on-success")...)
+	assert.NoError(t, err)
+	assert.Contains(t, stderr, "is in progress")
+	assert.Contains(t, stderr, "is succeeded")
+
+	var deployments = srv.Deployments(testOrgID, testAppID, testEnvID)
+	assert.Len(t, deployments, 1)
+	assert.Equal(t, ht.DeploymentStatusSucceeded, deployments[0].Status)
+	var result deltaResult
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.Equal(t, &deploymentResult{ID: deployments[0].ID, Status: ht.DeploymentStatusSucceeded}, result.Deployment)
+	assert.True(t, result.Delta.Metadata.Archived)
+	assert.Contains(t, srv.DeployedSet(testOrgID, testAppID, testEnvID).Modules, "web")
+	assert.True(t, srv.Deltas(testOrgID, testAppID)[0].Metadata.Archived)
+
+	// Failed deployments
+	srv.DeploymentStatus = ht.DeploymentStatusFailed
+	stdout, _, err = executeCommand(t, deltaArgs(srv, scoreFile, "--deploy", "--wait", "--archive-on-success")...)
+	assert.ErrorContains(t, err, "failed")
+	result = deltaResult{}
+	assert.NoError(t, json.NewDecoder(strings.NewReader(stdout)).Decode(&result)) // followed by the usage
+	assert.Equal(t, ht.DeploymentStatusFailed, result.Deployment.Status)
+	assert.False(t, srv.Deltas(testOrgID, testAppID)[1].Metadata.Archived)
+
+	// Deployment not started
+	srv.DeploymentStatus = ht.DeploymentStatusSucceeded
+	srv.FailNext(http.MethodPost, "/orgs/test-org/apps/test-app/envs/development/deploys", http.StatusBadRequest, 1)
+	stdout, _, err = executeCommand(t, deltaArgs(srv, scoreFile, "--deploy")...)
+	assert.ErrorContains(t, err, "unexpected response status 400")
+	result = deltaResult{}
+	assert.NoError(t, json.NewDecoder(strings.NewReader(stdout)).Decode(&result))
+	assert.Equal(t, srv.Deltas(testOrgID, testAppID)[2].ID, result.Delta.ID)
+	assert.Nil(t, result.Deployment)
+
+	// Delta not archived
+	var deltaID = srv.Deltas(testOrgID, testAppID)[2].ID
+	srv.FailNext(http.MethodPut, "/orgs/test-org/apps/test-app/deltas/"+deltaID+"/metadata/archived", http.StatusBadRequest, 1)
+	stdout, _, err = executeCommand(t, deltaArgs(srv, scoreFile, "--delta", deltaID, "--deploy", "--archive-on-success")...)
+	assert.ErrorContains(t, err, "archiving deployment delta '"+deltaID+"'")
+	result = deltaResult{}
+	assert.NoError(t, json.NewDecoder(strings.NewReader(stdout)).Decode(&result))
+	assert.Equal(t, deltaID, result.Delta.ID)
+	assert.False(t, result.Delta.Metadata.Archived)
+	assert.Equal(t, ht.DeploymentStatusSucceeded, result.Deployment.Status)
+}
+
+func TestDelta_output(t *testing.T) {
+	var srv = newFakeServer(t)
+	var dir = t.TempDir()
+	var scoreFile = writeFile(t, dir, "score.yaml", strings.Replace(testScoreFile, "DB_HOST: ${resources.db.host}", "DB_PORT: ${resources.nil.port}", 1))
+
+	// YAML to a file, with the conversion warnings
+	var outFile = filepath.Join(dir, "result.yaml")
+	stdout, _, err := executeCommand(t, deltaArgs(srv, scoreFile, "--format", "yaml", "--output", outFile)...)
+	assert.NoError(t, err)
+	assert.Empty(t, stdout)
+
+	raw, err := os.ReadFile(outFile)
+	assert.NoError(t, err)
+	var result map[string]interface{}
+	assert.NoError(t, yaml.Unmarshal(raw, &result))
+	assert.Equal(t, "score-humanitec/v1", result["apiVersion"])
+	assert.Equal(t, []interface{}{"workload 'web': Can not resolve '${resources.nil.port}' in containers.main.variables.DB_PORT."}, result["warnings"])
+	assert.Contains(t, result["delta"], "id")
+
+	// Compact JSON
+	stdout, _, err = executeCommand(t, deltaArgs(srv, scoreFile, "--format", "compact")...)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(stdout, "\n"))
+	assert.True(t, json.Valid([]byte(stdout)))
+
+	// Unsupported format
+	_, _, err = executeCommand(t, deltaArgs(srv, scoreFile, "--format", "xml")...)
+	assert.ErrorContains(t, err, "unsupported output format 'xml'")
+}
+
+func TestDelta_retries(t *testing.T) {
+	var srv = newFakeServer(t)
+	srv.FailNext(http.MethodPost, "/orgs/test-org/apps/test-app/deltas", http.StatusServiceUnavailable, 2)
+	var scoreFile = writeFile(t, t.TempDir(), "score.yaml", testScoreFile)
+
+	_, _, err := executeCommand(t, deltaArgs(srv, scoreFile)...)
+	assert.NoError(t, err)
+	assert.Len(t, srv.Deltas(testOrgID, testAppID), 1)
+
+	// Retries exhausted
+	srv.FailNext(http.MethodPost, "/orgs/test-org/apps/test-app/deltas", http.StatusServiceUnavailable, 2)
+	_, _, err = executeCommand(t, deltaArgs(srv, scoreFile, "--retry-max", "1")...)
+	assert.ErrorContains(t, err, "unexpected response status 503")
+	assert.Equal(t, ExitAPIUnavailable, ExitCode(err))
+}
+
+func TestDelta_ensureEnv(t *testing.T) {
+	var srv = newFakeServer(t)
+	var scoreFile = writeFile(t, t.TempDir(), "score.yaml", testScoreFile)
+
+	var args = []string{
+		"delta", "-f", scoreFile,
+		"--api-url", srv.URL, "--token", srv.Token,
+		"--org", testOrgID, "--app", testAppID, "--env", "pr-1",
+		"--ensure-env", "--base-env", testEnvID,
+	}
+
+	// Dry runs do not create the environment
+	_, stderr, err := executeCommand(t, append(args, "--prune", "--dry-run")...)
+	assert.NoError(t, err)
+	assert.Contains(t, stderr, "Nothing to prune.")
+	assert.Nil(t, srv.Environment(testOrgID, testAppID, "pr-1"))
+	for _, req := range srv.Requests() {
+		assert.False(t, req.Method == http.MethodPost && strings.HasSuffix(req.Path, "/envs"), "unexpected request %s %s", req.Method, req.Path)
+	}
+
+	_, _, err = executeCommand(t, append(args, "--deploy")...)
+	assert.NoError(t, err)
+
+	var env = srv.Environment(testOrgID, testAppID, "pr-1")
+	assert.NotNil(t, env)
+	assert.Equal(t, "development", env.Type)
+	assert.Contains(t, srv.DeployedSet(testOrgID, testAppID, "pr-1").Modules, "web")
+}
+
+func TestDelta_errors(t *testing.T) {
+	var srv = newFakeServer(t)
+	var dir = t.TempDir()
+	var scoreFile = writeFile(t, dir, "score.yaml", testScoreFile)
+	var unknownTypeFile = writeFile(t, dir, "unknown.yaml", `apiVersion: score.dev/v1b1
+metadata:
+  name: web
+containers:
+  main:
+    image: nginx
+resources:
+  queue:
+    type: unknown-queue
+`)
+
+	// Unknown resource types
+	_, _, err := executeCommand(t, deltaArgs(srv, unknownTypeFile)...)
+	assert.ErrorContains(t, err, "unknown-queue")
+	assert.Equal(t, ExitValidation, ExitCode(err))
+
+	// Invalid token
+	args := deltaArgs(srv, scoreFile)
+	args[6] = "invalid-token"
+	_, _, err = executeCommand(t, args...)
+	assert.Equal(t, ExitUnauthorized, ExitCode(err))
+	assert.Contains(t, ErrorHint(err), "token is invalid")
+
+	// Unknown environment
+	_, _, err = executeCommand(t, deltaArgs(srv, scoreFile, "--env", "unknown", "--skip-resource-validation")...)
+	assert.ErrorContains(t, err, "unexpected response status 422")
+	assert.Empty(t, srv.Deltas(testOrgID, testAppID))
+}
+
+func TestDelta_modeUpdate(t *testing.T) {
+	var srv = newFakeServer(t)
+	var scoreFile = writeFile(t, t.TempDir(), "score.yaml", testScoreFile)
+
+	_, _, err := executeCommand(t, deltaArgs(srv, scoreFile, "--deploy")...)
+	assert.NoError(t, err)
+
+	// Another tool adds a variable to the deployed module
+	client, err := api.NewClient(srv.URL, srv.Token, http.DefaultClient)
+	assert.NoError(t, err)
+	delta, err := client.CreateDelta(context.Background(), testOrgID, testAppID, &ht.CreateDeploymentDeltaRequest{
+		Metadata: ht.DeltaMetadata{EnvID: testEnvID},
+		Modules: ht.ModuleDeltas{
+			Update: map[string][]ht.UpdateAction{
+				"web": {{Operation: "add", Path: "/spec/containers/main/variables/EXTRA", Value: "value"}},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	_, err = client.StartDeployment(context.Background(), testOrgID, testAppID, testEnvID, false, &ht.StartDeploymentRequest{DeltaID: delta.ID})
+	assert.NoError(t, err)
+
+	// Only the changed SCORE values are patched
+	var updated = writeFile(t, t.TempDir(), "score.yaml", strings.Replace(testScoreFile, "image: nginx", "image: nginx:1.25", 1))
+	stdout, _, err := executeCommand(t, deltaArgs(srv, updated, "--mode", "update", "--deploy")...)
+	assert.NoError(t, err)
+
+	var result deltaResult
+	assert.NoError(t, json.NewDecoder(strings.NewReader(stdout)).Decode(&result))
+	assert.Empty(t, result.Delta.Modules.Add)
+	assert.Equal(t, map[string][]ht.UpdateAction{
+		"web": {
+			{Operation: "add", Path: "/spec/annotations/humanitec.io~1owned-paths", Value: `["/externals/db/class","/externals/db/type","/profile","/spec/annotations/humanitec.io~1managed-by","/spec/containers/main/id","/spec/containers/main/image","/spec/containers/main/variables/DB_HOST"]`},
+			{Operation: "replace", Path: "/spec/containers/main/image", Value: "nginx:1.25"},
+		},
+	}, result.Delta.Modules.Update)
+
+	var container = srv.DeployedSet(testOrgID, testAppID, testEnvID).Modules["web"]["spec"].(map[string]interface{})["containers"].(map[string]interface{})["main"].(map[string]interface{})
+	assert.Equal(t, "nginx:1.25", container["image"])
+	assert.Equal(t, "value", container["variables"].(map[string]interface{})["EXTRA"])
+
+	// The same changes are previewed by diff
+	stdout, _, err = executeCommand(t, "diff", "-f", updated, "--mode", "update", "--no-color",
+		"--api-url", srv.URL, "--token", srv.Token, "--org", testOrgID, "--app", testAppID, "--env", testEnvID)
+	assert.NoError(t, err)
+	assert.Contains(t, stdout, "No changes.")
+
+	// The SCORE values removed since the last update are removed as well
+	var removed = writeFile(t, t.TempDir(), "score.yaml", strings.Replace(testScoreFile, "    variables:\n      DB_HOST: ${resources.db.host}\n", "", 1))
+	_, _, err = executeCommand(t, deltaArgs(srv, removed, "--mode", "update", "--deploy")...)
+	assert.NoError(t, err)
+
+	container = srv.DeployedSet(testOrgID, testAppID, testEnvID).Modules["web"]["spec"].(map[string]interface{})["containers"].(map[string]interface{})["main"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"EXTRA": "value"}, container["variables"])
+
+	// Unsupported modes
+	_, _, err = executeCommand(t, deltaArgs(srv, scoreFile, "--mode", "replace")...)
+	assert.EqualError(t, err, "unsupported mode 'replace', expected 'add' or 'update'")
+}
+
+func TestDelta_prune(t *testing.T) {
+	var srv = newFakeServer(t)
+	var dir = t.TempDir()
+	var scoreFile = writeFile(t, dir, "score.yaml", testScoreFile)
+	var workerFile = writeFile(t, dir, "worker.yaml", `apiVersion: score.dev/v1b1
+metadata:
+  name: worker
+containers:
+  main:
+    image: busybox
+    variables:
+      DNS: ${resources.dns.host}
+resources:
+  dns:
+    type: dns
+    metadata:
+      annotations:
+        score.humanitec.io/resId: shared.dns
+`)
+
+	// The shared resources are only recorded when pruning
+	_, _, err := executeCommand(t, deltaArgs(srv, scoreFile, "-f", workerFile, "--skip-resource-validation", "--deploy")...)
+	assert.NoError(t, err)
+	var set = srv.DeployedSet(testOrgID, testAppID, testEnvID)
+	assert.NotContains(t, set.Modules["worker"]["spec"].(map[string]interface{})["annotations"], "humanitec.io/shared-resources")
+
+	_, _, err = executeCommand(t, deltaArgs(srv, scoreFile, "-f", workerFile, "--skip-resource-validation", "--prune", "--deploy")...)
+	assert.NoError(t, err)
+	set = srv.DeployedSet(testOrgID, testAppID, testEnvID)
+	assert.Contains(t, set.Modules, "worker")
+	assert.Equal(t, `["dns"]`, set.Modules["worker"]["spec"].(map[string]interface{})["annotations"].(map[string]interface{})["humanitec.io/shared-resources"])
+	assert.Contains(t, set.Shared, "dns")
+
+	// Dry run
+	stdout, stderr, err := executeCommand(t, deltaArgs(srv, scoreFile, "--prune", "--dry-run")...)
+	assert.NoError(t, err)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "The following would be removed (dry run):\n  - module 'worker'\n  - shared resource 'dns'\n")
+	assert.Len(t, srv.Deltas(testOrgID, testAppID), 2)
+
+	// Prune
+	stdout, stderr, err = executeCommand(t, deltaArgs(srv, scoreFile, "--prune", "--deploy")...)
+	assert.NoError(t, err)
+	assert.Contains(t, stderr, "The following will be removed:")
+	var result deltaResult
+	assert.NoError(t, json.NewDecoder(strings.NewReader(stdout)).Decode(&result))
+	assert.Equal(t, []string{"worker"}, result.Delta.Modules.Remove)
+	assert.Equal(t, []string{"worker"}, result.Pruned.Modules)
+	assert.Equal(t, []string{"dns"}, result.Pruned.Shared)
+
+	set = srv.DeployedSet(testOrgID, testAppID, testEnvID)
+	assert.Contains(t, set.Modules, "web")
+	assert.NotContains(t, set.Modules, "worker")
+	assert.NotContains(t, set.Shared, "dns")
+
+	// Nothing left to prune
+	_, stderr, err = executeCommand(t, deltaArgs(srv, scoreFile, "--prune", "--dry-run")...)
+	assert.NoError(t, err)
+	assert.Contains(t, stderr, "Nothing to prune.")
+
+	// Up to date workloads are not pruned in update mode
+	stdout, stderr, err = executeCommand(t, deltaArgs(srv, scoreFile, "--mode", "update", "--prune", "--deploy")...)
+	assert.NoError(t, err)
+	assert.Contains(t, stderr, "Nothing to prune.")
+	result = deltaResult{}
+	assert.NoError(t, json.NewDecoder(strings.NewReader(stdout)).Decode(&result))
+	assert.Empty(t, result.Delta.Modules.Remove)
+	assert.Contains(t, srv.DeployedSet(testOrgID, testAppID, testEnvID).Modules, "web")
+
+	// The diff command accepts the same flags as the delta command
+	stdout, _, err = executeCommand(t, "diff", "-f", scoreFile, "--mode", "update", "--prune", "--no-color", "--message", "Preview",
+		"--api-url", srv.URL, "--token", srv.Token, "--org", testOrgID, "--app", testAppID, "--env", testEnvID)
+	assert.NoError(t, err)
+	assert.Contains(t, stdout, "No changes.")
+
+	_, _, err = executeCommand(t, deltaArgs(srv, scoreFile, "--dry-run")...)
+	assert.EqualError(t, err, "the --dry-run flag requires the --prune flag")
+}
+
+func TestDelta_ownership(t *testing.T) {
+	var srv = newFakeServer(t)
+	var scoreFile = writeFile(t, t.TempDir(), "score.yaml", testScoreFile)
+
+	// A hand-crafted module with the same name is deployed
+	client, err := api.NewClient(srv.URL, srv.Token, http.DefaultClient)
+	assert.NoError(t, err)
+	delta, err := client.CreateDelta(context.Background(), testOrgID, testAppID, &ht.CreateDeploymentDeltaRequest{
+		Metadata: ht.DeltaMetadata{EnvID: testEnvID},
+		Modules: ht.ModuleDeltas{
+			Add: map[string]map[string]interface{}{
+				"web": {"profile": "humanitec/default-module", "spec": map[string]interface{}{"replicas": 2}},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	_, err = client.StartDeployment(context.Background(), testOrgID, testAppID, testEnvID, false, &ht.StartDeploymentRequest{DeltaID: delta.ID})
+	assert.NoError(t, err)
+
+	_, _, err = executeCommand(t, deltaArgs(srv, scoreFile)...)
+	assert.EqualError(t, err, "ownership check failed:\n  - module 'web': not managed by score-humanitec")
+	assert.Equal(t, ExitConflict, ExitCode(err))
+	assert.Contains(t, ErrorHint(err), "--force-adopt")
+	assert.Len(t, srv.Deltas(testOrgID, testAppID), 1)
+
+	// Take ownership
+	_, _, err = executeCommand(t, deltaArgs(srv, scoreFile, "--force-adopt", "--workload-source-url", "https://git.example.com/a", "--deploy")...)
+	assert.NoError(t, err)
+	_, _, err = executeCommand(t, deltaArgs(srv, scoreFile, "--workload-source-url", "https://git.example.com/a")...)
+	assert.NoError(t, err)
+
+	// Another repository
+	_, _, err = executeCommand(t, deltaArgs(srv, scoreFile, "--workload-source-url", "https://git.example.com/b")...)
+	assert.EqualError(t, err, "ownership check failed:\n  - module 'web': managed from 'https://git.example.com/a'")
+
+	// Unknown repository
+	_, _, err = executeCommand(t, deltaArgs(srv, scoreFile)...)
+	assert.EqualError(t, err, "ownership check failed:\n  - module 'web': managed from 'https://git.example.com/a'")
+	_, _, err = executeCommand(t, deltaArgs(srv, scoreFile, "--force-adopt")...)
+	assert.NoError(t, err)
+}
+
+func TestDraft_skipResourceValidation(t *testing.T) {
+	var srv = newFakeServer(t)
+	var scoreFile = writeFile(t, t.TempDir(), "score.yaml", strings.Replace(testScoreFile, "type: postgres", "type: unknown-queue", 1))
+	var args = []string{
+		"draft", "-f", scoreFile,
+		"--api-url", srv.URL, "--token", srv.Token,
+		"--org", testOrgID, "--app", testAppID, "--env", testEnvID,
+	}
+
+	_, _, err := executeCommand(t, args...)
+	assert.ErrorContains(t, err, "unknown-queue")
+
+	_, _, err = executeCommand(t, append(args, "--skip-resource-validation")...)
+	assert.NoError(t, err)
+	assert.Len(t, srv.Deltas(testOrgID, testAppID), 1)
+}
@@ -0,0 +1,167 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package command
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	deltasCmd.PersistentFlags().StringVar(&apiUrl, "api-url", apiUrlDefault, "Humanitec API endpoint")
+	deltasCmd.PersistentFlags().StringVar(&apiToken, "token", "", "Humanitec API authentication token")
+	deltasCmd.MarkPersistentFlagRequired("token")
+	deltasCmd.PersistentFlags().StringVar(&orgID, "org", "", "Organization ID")
+	deltasCmd.MarkPersistentFlagRequired("org")
+	deltasCmd.PersistentFlags().StringVar(&appID, "app", "", "Application ID")
+	deltasCmd.MarkPersistentFlagRequired("app")
+	deltasCmd.PersistentFlags().IntVar(&retryMax, "retry-max", retryMaxDefault, "Maximum number of retries for transient Humanitec API errors (0 disables retries)")
+	deltasCmd.PersistentFlags().DurationVar(&retryTimeout, "retry-timeout", retryTimeoutDefault, "Maximum time to spend retrying a Humanitec API call")
+	addTransportFlags(deltasCmd.PersistentFlags())
+	deltasCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Enable diagnostic messages (written to STDERR)")
+
+	deltasListCmd.Flags().StringVar(&envID, "env", "", "Only list deltas for the environment ID")
+	deltasListCmd.Flags().SetAnnotation("env", filterFlagAnnotation, []string{"true"})
+	deltasListCmd.Flags().BoolVar(&archived, "archived", false, "List archived deltas instead of the active ones")
+	deltasListCmd.Flags().StringVar(&outputFormat, "format", formatTable, "Output format: table, json, yaml or compact (single line JSON)")
+	deltasShowCmd.Flags().StringVar(&outputFormat, "format", formatTable, "Output format: table, json, yaml or compact (single line JSON)")
+
+	deltasCmd.AddCommand(deltasListCmd)
+	deltasCmd.AddCommand(deltasShowCmd)
+	deltasCmd.AddCommand(deltasArchiveCmd)
+	deltasCmd.AddCommand(deltasDeleteCmd)
+	rootCmd.AddCommand(deltasCmd)
+}
+
+var deltasCmd = &cobra.Command{
+	Use:   "deltas",
+	Short: "Manages Humanitec deployment deltas",
+}
+
+var deltasListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "Lists the deployment deltas of the application",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		setupLogging()
+		if err := validateAppIDs(); err != nil {
+			return err
+		}
+		if err := validateFormat(); err != nil {
+			return err
+		}
+
+		client, err := newApiClient()
+		if err != nil {
+			return err
+		}
+		log.Print("Listing deployment deltas...\n")
+		res, err := client.ListDeltas(cmd.Context(), orgID, appID, envID, archived)
+		if err != nil {
+			return err
+		}
+
+		if outputFormat != formatTable {
+			return writeFormatted(cmd.OutOrStdout(), outputFormat, res)
+		}
+		var w = tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tENV\tNAME\tCONTRIBUTORS\tCREATED\tMODIFIED")
+		for _, delta := range res {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", delta.ID, delta.Metadata.EnvID, delta.Metadata.Name,
+				strings.Join(delta.Metadata.Contributers, ","), delta.Metadata.CreatedAt, delta.Metadata.ModifiedAt)
+		}
+		return w.Flush()
+	},
+}
+
+var deltasShowCmd = &cobra.Command{
+	Use:          "show DELTA_ID",
+	Short:        "Shows the deployment delta",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		setupLogging()
+		if err := validateAppIDs(); err != nil {
+			return err
+		}
+		if err := validateFormat(); err != nil {
+			return err
+		}
+
+		client, err := newApiClient()
+		if err != nil {
+			return err
+		}
+		log.Printf("Fetching deployment delta '%s'...\n", args[0])
+		res, err := client.GetDelta(cmd.Context(), orgID, appID, args[0])
+		if err != nil {
+			return err
+		}
+
+		if outputFormat != formatTable {
+			return writeFormatted(cmd.OutOrStdout(), outputFormat, res)
+		}
+		printDeltaMetadata(cmd.OutOrStdout(), res)
+		return nil
+	},
+}
+
+var deltasArchiveCmd = &cobra.Command{
+	Use:          "archive DELTA_ID...",
+	Short:        "Archives the deployment deltas",
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		setupLogging()
+		if err := validateAppIDs(); err != nil {
+			return err
+		}
+		client, err := newApiClient()
+		if err != nil {
+			return err
+		}
+		for _, id := range args {
+			log.Printf("Archiving deployment delta '%s'...\n", id)
+			if err := client.ArchiveDelta(cmd.Context(), orgID, appID, id); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deployment delta '%s' archived\n", id)
+		}
+		return nil
+	},
+}
+
+var deltasDeleteCmd = &cobra.Command{
+	Use:          "delete DELTA_ID...",
+	Short:        "Deletes the deployment deltas",
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		setupLogging()
+		if err := validateAppIDs(); err != nil {
+			return err
+		}
+		client, err := newApiClient()
+		if err != nil {
+			return err
+		}
+		for _, id := range args {
+			log.Printf("Deleting deployment delta '%s'...\n", id)
+			if err := client.DeleteDelta(cmd.Context(), orgID, appID, id); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deployment delta '%s' deleted\n", id)
+		}
+		return nil
+	},
+}
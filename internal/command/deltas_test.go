@@ -0,0 +1,72 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v3"
+
+	ht "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+	"github.com/score-spec/score-humanitec/pkg/humanitec/fake"
+)
+
+// deltasArgs returns the arguments of the 'deltas' sub-command, with the fake server API settings.
+func deltasArgs(srv *fake.Server, subCommand string, extra ...string) []string {
+	return append([]string{
+		"deltas", subCommand,
+		"--api-url", srv.URL, "--token", srv.Token,
+		"--org", testOrgID, "--app", testAppID,
+	}, extra...)
+}
+
+func TestDeltas_format(t *testing.T) {
+	var srv = newFakeServer(t)
+	var scoreFile = writeFile(t, t.TempDir(), "score.yaml", testScoreFile)
+	stdout, _, err := executeCommand(t, deltaArgs(srv, scoreFile)...)
+	assert.NoError(t, err)
+	var result deltaResult
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &result))
+
+	stdout, _, err = executeCommand(t, deltasArgs(srv, "list")...)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(stdout, "ID"))
+	assert.Contains(t, stdout, result.Delta.ID)
+
+	stdout, _, err = executeCommand(t, deltasArgs(srv, "list", "--format", "yaml")...)
+	assert.NoError(t, err)
+	var deltas []ht.DeploymentDelta
+	assert.NoError(t, yaml.Unmarshal([]byte(stdout), &deltas))
+	assert.Len(t, deltas, 1)
+
+	stdout, _, err = executeCommand(t, deltasArgs(srv, "show", result.Delta.ID, "--format", "compact")...)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(stdout, "\n"))
+	var delta ht.DeploymentDelta
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &delta))
+	assert.Equal(t, result.Delta.ID, delta.ID)
+
+	_, _, err = executeCommand(t, deltasArgs(srv, "list", "--format", "xml")...)
+	assert.EqualError(t, err, "unsupported output format 'xml', expected 'table', 'json', 'yaml' or 'compact'")
+}
+
+func TestDeltas_validateIDs(t *testing.T) {
+	var srv = newFakeServer(t)
+
+	for _, args := range [][]string{{"list"}, {"show", "abc"}, {"archive", "abc"}, {"delete", "abc"}} {
+		_, _, err := executeCommand(t, append(deltasArgs(srv, args[0], args[1:]...), "--org", "Test Org")...)
+		assert.EqualError(t, err, "invalid organization id 'Test Org'. Did you use the organization name instead of the id?")
+	}
+
+	_, _, err := executeCommand(t, deltasArgs(srv, "list", "--env", "Development")...)
+	assert.EqualError(t, err, "invalid environment id 'Development'. Did you use the environment name instead of the id?")
+	assert.Empty(t, srv.Requests())
+}
@@ -0,0 +1,239 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/score-spec/score-humanitec/internal/humanitec"
+	api "github.com/score-spec/score-humanitec/internal/humanitec_go/client"
+)
+
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorBold   = "\033[1m"
+)
+
+func init() {
+	diffCmd.Flags().StringArrayVarP(&scoreFiles, "file", "f", []string{scoreFileDefault}, "Source SCORE file (can be repeated or use a glob pattern)")
+	diffCmd.Flags().StringVar(&overridesFile, "overrides", overridesFileDefault, "Overrides file")
+	diffCmd.Flags().StringVar(&extensionsFile, "extensions", extensionsFileDefault, "Extensions file")
+	diffCmd.Flags().StringVar(&workloadSourceURL, "workload-source-url", "", "URL of file that is managing the humanitec workload")
+	diffCmd.Flags().StringVarP(&message, "message", "m", messageDefault, "Message")
+	diffCmd.Flags().StringVar(&apiUrl, "api-url", apiUrlDefault, "Humanitec API endpoint")
+	diffCmd.Flags().BoolVar(&prune, "prune", false, "Include the removals of the 'delta --prune' flag")
+	diffCmd.Flags().StringVar(&deltaMode, "mode", modeAdd, "How deployed modules are changed: 'add' replaces them, 'update' patches the fields generated from the SCORE files only")
+
+	diffCmd.Flags().StringVar(&apiToken, "token", "", "Humanitec API authentication token")
+	diffCmd.MarkFlagRequired("token")
+	diffCmd.Flags().StringVar(&orgID, "org", "", "Organization ID")
+	diffCmd.MarkFlagRequired("org")
+	diffCmd.Flags().StringVar(&appID, "app", "", "Application ID")
+	diffCmd.MarkFlagRequired("app")
+	diffCmd.Flags().StringVar(&envID, "env", "", "Environment ID")
+	diffCmd.MarkFlagRequired("env")
+
+	diffCmd.Flags().StringArrayVarP(&overrideParams, "property", "p", nil, "Overrides selected property value")
+	diffCmd.Flags().StringVarP(&currentImage, "image", "i", ".", "Image to use for the current image, signified by \".\"")
+
+	diffCmd.Flags().IntVar(&retryMax, "retry-max", retryMaxDefault, "Maximum number of retries for transient Humanitec API errors (0 disables retries)")
+	diffCmd.Flags().DurationVar(&retryTimeout, "retry-timeout", retryTimeoutDefault, "Maximum time to spend retrying a Humanitec API call")
+	addTransportFlags(diffCmd.Flags())
+	diffCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+	diffCmd.Flags().BoolVar(&strict, "strict", false, "Fail if any '${...}' reference can not be resolved")
+	diffCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "DEPRECATED: Disables Score file schema validation.")
+	diffCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable diagnostic messages (written to STDERR)")
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Shows the changes the SCORE file would make to the Humanitec environment",
+	Long: `This command will translate the SCORE file into a Humanitec deployment delta, apply it to the deployment set
+currently deployed in the environment specified by the --org, --app, and --env flags, and print the resulting changes
+for each module and shared resource. Nothing is changed in Humanitec. The --mode and --prune flags preview the
+changes of the matching 'delta' flags.
+`,
+	RunE: diff,
+}
+
+func diff(cmd *cobra.Command, args []string) error {
+	setupLogging()
+
+	// Load SCORE specs and extensions
+	//
+	workloads, err := loadWorkloads(scoreFiles, overridesFile, extensionsFile, skipValidation)
+	if err != nil {
+		return err
+	}
+
+	// Validate the ID
+	if err := validateIDs(); err != nil {
+		return err
+	}
+	if err := validateDeltaMode(); err != nil {
+		return err
+	}
+
+	// Prepare a new deployment
+	//
+	log.Print("Preparing a new deployment...\n")
+	delta, warnings, err := humanitec.ConvertSpecs(message, envID, workloadSourceURL, workloads, strict)
+	if err != nil {
+		return fmt.Errorf("preparing new deployment: %w", err)
+	}
+	logWarnings(warnings)
+
+	client, err := newApiClient()
+	if err != nil {
+		return err
+	}
+
+	// Apply the delta to the deployed set
+	//
+	log.Printf("Fetching the deployment set for environment '%s'...\n", envID)
+	before, err := api.GetDeployedSet(cmd.Context(), client, orgID, appID, envID)
+	if err != nil {
+		return err
+	}
+	if prune {
+		humanitec.AnnotateSharedResources(delta, workloads)
+	}
+	if deltaMode == modeUpdate {
+		if delta, err = humanitec.UpdateModules(delta, before); err != nil {
+			return fmt.Errorf("preparing module updates: %w", err)
+		}
+	}
+	if prune {
+		var pruned *humanitec.PruneSummary
+		if delta, pruned, err = humanitec.PruneDelta(delta, before, workloadNames(workloads)); err != nil {
+			return fmt.Errorf("preparing removals: %w", err)
+		}
+		logWarnings(pruned.Warnings)
+	}
+	after, err := humanitec.ApplyDelta(before, delta)
+	if err != nil {
+		return fmt.Errorf("applying deployment delta: %w", err)
+	}
+
+	// Output the changes
+	//
+	var p = diffPrinter{out: cmd.OutOrStdout(), color: useColor()}
+	var changed = p.printSection("Module", toValues(before.Modules), toValues(after.Modules))
+	changed = p.printSection("Shared resource", before.Shared, after.Shared) || changed
+	if !changed {
+		fmt.Fprintln(cmd.OutOrStdout(), "No changes.")
+	}
+
+	return nil
+}
+
+// useColor returns true if the colored output should be used.
+func useColor() bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	stat, err := os.Stdout.Stat()
+	return err == nil && (stat.Mode()&os.ModeCharDevice) != 0
+}
+
+func toValues(src map[string]map[string]interface{}) map[string]interface{} {
+	var dst = make(map[string]interface{}, len(src))
+	for key, val := range src {
+		dst[key] = val
+	}
+	return dst
+}
+
+// diffPrinter writes human readable changes between deployment sets.
+type diffPrinter struct {
+	out   io.Writer
+	color bool
+}
+
+// printSection writes the changes for every named entry in the section.
+// Returns true if there were any changes.
+func (p *diffPrinter) printSection(title string, before, after map[string]interface{}) bool {
+	var names = make([]string, 0, len(after))
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, exists := before[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changed bool
+	for _, name := range names {
+		var changes = humanitec.Diff(before[name], after[name])
+		if len(changes) == 0 {
+			continue
+		}
+		changed = true
+
+		switch {
+		case before[name] == nil:
+			p.printf(colorBold+colorGreen, "+ %s '%s' will be added\n", title, name)
+			p.printf(colorGreen, "%s\n", indent(after[name], "    "))
+		case after[name] == nil:
+			p.printf(colorBold+colorRed, "- %s '%s' will be removed\n", title, name)
+		default:
+			p.printf(colorBold+colorYellow, "~ %s '%s' will be changed\n", title, name)
+			for _, change := range changes {
+				switch change.Operation() {
+				case "add":
+					p.printf(colorGreen, "    + %s: %s\n", change.Path, compact(change.After))
+				case "remove":
+					p.printf(colorRed, "    - %s: %s\n", change.Path, compact(change.Before))
+				default:
+					p.printf(colorYellow, "    ~ %s: %s => %s\n", change.Path, compact(change.Before), compact(change.After))
+				}
+			}
+		}
+		fmt.Fprintln(p.out)
+	}
+
+	return changed
+}
+
+func (p *diffPrinter) printf(color, format string, args ...interface{}) {
+	if p.color {
+		fmt.Fprint(p.out, color)
+		defer fmt.Fprint(p.out, colorReset)
+	}
+	fmt.Fprintf(p.out, format, args...)
+}
+
+func compact(val interface{}) string {
+	raw, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%v", val)
+	}
+	return string(raw)
+}
+
+func indent(val interface{}, prefix string) string {
+	raw, err := json.MarshalIndent(val, prefix, "  ")
+	if err != nil {
+		return prefix + fmt.Sprintf("%v", val)
+	}
+	return prefix + string(raw)
+}
@@ -12,12 +12,13 @@ import (
 )
 
 func init() {
-	draftCmd.Flags().StringVarP(&scoreFile, "file", "f", scoreFileDefault, "Source SCORE file")
+	draftCmd.Flags().StringArrayVarP(&scoreFiles, "file", "f", []string{scoreFileDefault}, "Source SCORE file (can be repeated or use a glob pattern)")
 	draftCmd.Flags().StringVar(&overridesFile, "overrides", overridesFileDefault, "Overrides file")
 	draftCmd.Flags().StringVar(&extensionsFile, "extensions", extensionsFileDefault, "Extensions file")
 	draftCmd.Flags().StringVar(&workloadSourceURL, "workload-source-url", "", "URL of file that is managing the humanitec workload")
 	draftCmd.Flags().StringVar(&uiUrl, "ui-url", uiUrlDefault, "Humanitec API endpoint")
 	draftCmd.Flags().StringVar(&apiUrl, "api-url", apiUrlDefault, "Humanitec API endpoint")
+	draftCmd.Flags().StringVar(&resourceTypesFile, "resource-types-file", "", "Cached JSON list of the organization resource types to validate resources against (skips the API call)")
 	draftCmd.Flags().StringVar(&apiToken, "token", "", "Humanitec API authentication token")
 	draftCmd.MarkFlagRequired("token")
 	draftCmd.Flags().StringVar(&orgID, "org", "", "Organization ID")
@@ -31,6 +32,10 @@ func init() {
 	draftCmd.Flags().StringVarP(&currentImage, "image", "i", ".", "Image to use for the current image, signified by \".\"")
 
 	draftCmd.Flags().BoolVar(&deploy, "deploy", false, "Trigger a new draft deployment at the end")
+	draftCmd.Flags().IntVar(&retryMax, "retry-max", retryMaxDefault, "Maximum number of retries for transient Humanitec API errors (0 disables retries)")
+	draftCmd.Flags().DurationVar(&retryTimeout, "retry-timeout", retryTimeoutDefault, "Maximum time to spend retrying a Humanitec API call")
+	addTransportFlags(draftCmd.Flags())
+	draftCmd.Flags().BoolVar(&skipResourceValidation, "skip-resource-validation", false, "Disables validation of resources against the organization resource types")
 	draftCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable diagnostic messages (written to STDERR)")
 
 	rootCmd.AddCommand(draftCmd)
@@ -0,0 +1,133 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	api "github.com/score-spec/score-humanitec/internal/humanitec_go/client"
+	ht "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+func init() {
+	envCmd.PersistentFlags().StringVar(&apiUrl, "api-url", apiUrlDefault, "Humanitec API endpoint")
+	envCmd.PersistentFlags().StringVar(&apiToken, "token", "", "Humanitec API authentication token")
+	envCmd.MarkPersistentFlagRequired("token")
+	envCmd.PersistentFlags().StringVar(&orgID, "org", "", "Organization ID")
+	envCmd.MarkPersistentFlagRequired("org")
+	envCmd.PersistentFlags().StringVar(&appID, "app", "", "Application ID")
+	envCmd.MarkPersistentFlagRequired("app")
+	envCmd.PersistentFlags().IntVar(&retryMax, "retry-max", retryMaxDefault, "Maximum number of retries for transient Humanitec API errors (0 disables retries)")
+	envCmd.PersistentFlags().DurationVar(&retryTimeout, "retry-timeout", retryTimeoutDefault, "Maximum time to spend retrying a Humanitec API call")
+	addTransportFlags(envCmd.PersistentFlags())
+	envCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Enable diagnostic messages (written to STDERR)")
+
+	envCreateCmd.Flags().StringVar(&baseEnvID, "from", "", "Base environment ID to clone the last deployment from")
+	envCreateCmd.Flags().StringVar(&envName, "name", "", "Environment name (defaults to the environment ID)")
+	envCreateCmd.Flags().StringVar(&envType, "type", envTypeDefault, "Environment type (ignored if --from is set, the base environment type is used instead)")
+
+	envCmd.AddCommand(envCreateCmd)
+	envCmd.AddCommand(envDeleteCmd)
+	rootCmd.AddCommand(envCmd)
+}
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Manages Humanitec environments",
+}
+
+var envCreateCmd = &cobra.Command{
+	Use:   "create ENV_ID",
+	Short: "Creates a new environment",
+	Long: `This command creates a new environment in the application specified by the --org and --app flags.
+If the --from flag is provided, the new environment starts from the last deployment of the base environment,
+e.g. to create a preview environment for a pull request.
+`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		setupLogging()
+		if !validID.MatchString(args[0]) {
+			return fmt.Errorf("invalid environment id '%s'", args[0])
+		}
+
+		client, err := newApiClient()
+		if err != nil {
+			return err
+		}
+
+		var env *ht.Environment
+		if baseEnvID != "" {
+			env, err = cloneEnvironment(cmd.Context(), client, baseEnvID, args[0])
+		} else {
+			if envName == "" {
+				envName = args[0]
+			}
+			log.Printf("Creating environment '%s'...\n", args[0])
+			env, err = client.CreateEnvironment(cmd.Context(), orgID, appID, &ht.CreateEnvironmentRequest{
+				ID:   args[0],
+				Name: envName,
+				Type: envType,
+			})
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Environment '%s' created\n", env.ID)
+		return nil
+	},
+}
+
+var envDeleteCmd = &cobra.Command{
+	Use:          "delete ENV_ID",
+	Short:        "Deletes the environment",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		setupLogging()
+		client, err := newApiClient()
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Deleting environment '%s'...\n", args[0])
+		if err := client.DeleteEnvironment(cmd.Context(), orgID, appID, args[0]); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Environment '%s' deleted\n", args[0])
+		return nil
+	},
+}
+
+// cloneEnvironment creates the environment from the last deployment of the base environment.
+func cloneEnvironment(ctx context.Context, client api.Client, baseEnvID, envID string) (*ht.Environment, error) {
+	log.Printf("Creating environment '%s' from '%s'...\n", envID, baseEnvID)
+	return api.CloneEnvironment(ctx, client, orgID, appID, baseEnvID, envID, envName)
+}
+
+// ensureEnvironment creates the --env environment from the --base-env environment, unless it exists already.
+func ensureEnvironment(ctx context.Context, client api.Client) error {
+	log.Printf("Checking environment '%s'...\n", envID)
+	_, err := client.GetEnvironment(ctx, orgID, appID, envID)
+	if err == nil {
+		return nil
+	} else if !api.IsNotFound(err) {
+		return err
+	}
+
+	if _, err := cloneEnvironment(ctx, client, baseEnvID, envID); err != nil {
+		return fmt.Errorf("creating environment '%s': %w", envID, err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package command
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/score-spec/score-humanitec/internal/humanitec"
+	api "github.com/score-spec/score-humanitec/internal/humanitec_go/client"
+)
+
+// Process exit codes
+const (
+	ExitError          = 1 // Generic error
+	ExitUnauthorized   = 3 // Humanitec API token is missing, invalid or expired
+	ExitForbidden      = 4 // Humanitec API token lacks the required permissions
+	ExitNotFound       = 5 // Humanitec organization, application, environment or object does not exist
+	ExitConflict       = 6 // Conflicting Humanitec state, e.g. a deployment is already in progress
+	ExitValidation     = 7 // Invalid Score files, generated delta or rejected API request
+	ExitAPIUnavailable = 8 // Humanitec API is temporary unavailable or rate limited
+)
+
+// ExitCode returns the process exit code for the error.
+func ExitCode(err error) int {
+	var apiErr *api.APIError
+	var validationErr *humanitec.ValidationError
+	var unresolvedErr *humanitec.UnresolvedReferencesError
+	var ownershipErr *humanitec.OwnershipError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &apiErr):
+		switch {
+		case apiErr.StatusCode == http.StatusUnauthorized:
+			return ExitUnauthorized
+		case apiErr.StatusCode == http.StatusForbidden:
+			return ExitForbidden
+		case apiErr.StatusCode == http.StatusNotFound:
+			return ExitNotFound
+		case apiErr.StatusCode == http.StatusConflict:
+			return ExitConflict
+		case apiErr.StatusCode == http.StatusBadRequest, apiErr.StatusCode == http.StatusUnprocessableEntity:
+			return ExitValidation
+		case apiErr.Retryable, apiErr.StatusCode >= 500:
+			return ExitAPIUnavailable
+		}
+	case errors.As(err, &validationErr), errors.As(err, &unresolvedErr):
+		return ExitValidation
+	case errors.As(err, &ownershipErr):
+		return ExitConflict
+	}
+	return ExitError
+}
+
+// ErrorHint returns a human friendly suggestion on how to fix the error, or an empty string.
+func ErrorHint(err error) string {
+	var ownershipErr *humanitec.OwnershipError
+	if errors.As(err, &ownershipErr) {
+		return "rename the workloads, or use --force-adopt to take ownership of the deployed modules"
+	}
+
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) {
+		return ""
+	}
+
+	var params = apiPathParams(apiErr.URL)
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized:
+		return "the Humanitec API token is invalid or expired, check the --token flag or HUMANITEC_TOKEN environment variable"
+
+	case http.StatusForbidden:
+		switch {
+		case params["deploys"] != "" || strings.HasSuffix(apiErr.URL, "/deploys"):
+			return fmt.Sprintf("the API token lacks the Deployer role on environment '%s' of application '%s'", params["envs"], params["apps"])
+		case params["apps"] != "":
+			return fmt.Sprintf("the API token lacks the Developer role on application '%s'", params["apps"])
+		default:
+			return fmt.Sprintf("the API token lacks access to organization '%s'", params["orgs"])
+		}
+
+	case http.StatusNotFound:
+		switch {
+		case params["deltas"] != "":
+			return fmt.Sprintf("deployment delta '%s' does not exist in application '%s'", params["deltas"], params["apps"])
+		case params["envs"] != "":
+			return fmt.Sprintf("check that environment '%s' exists in application '%s'", params["envs"], params["apps"])
+		case params["apps"] != "":
+			return fmt.Sprintf("check that application '%s' exists in organization '%s'", params["apps"], params["orgs"])
+		default:
+			return fmt.Sprintf("check that organization '%s' exists and the --api-url is correct", params["orgs"])
+		}
+
+	case http.StatusConflict:
+		if strings.HasSuffix(apiErr.URL, "/deploys") {
+			return fmt.Sprintf("a deployment is already in progress in environment '%s', use --retry to wait for it", params["envs"])
+		}
+
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		if apiErr.Message != "" {
+			return fmt.Sprintf("the request was rejected by Humanitec: %s", apiErr.Message)
+		}
+	}
+
+	if apiErr.Retryable || apiErr.StatusCode >= 500 {
+		return "the Humanitec API is not available at the moment, try again later or increase --retry-max and --retry-timeout"
+	}
+	return ""
+}
+
+// apiPathParams extracts the object IDs from the Humanitec API URL path,
+// e.g. '/orgs/my-org/apps/my-app' results in {"orgs": "my-org", "apps": "my-app"}.
+func apiPathParams(apiUrl string) map[string]string {
+	var params = make(map[string]string)
+	u, err := url.Parse(apiUrl)
+	if err != nil {
+		return params
+	}
+	var segments = strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		switch segments[i] {
+		case "orgs", "apps", "envs", "deltas", "deploys", "sets":
+			params[segments[i]] = segments[i+1]
+		}
+	}
+	return params
+}
@@ -0,0 +1,85 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package command
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+// executeCommand runs the CLI with the given arguments and returns the captured STDOUT and STDERR.
+// All the flags are reset to their defaults, and the user configuration and environment are ignored.
+func executeCommand(t *testing.T, args ...string) (string, string, error) {
+	return executeCommandWithConfig(t, "", args...)
+}
+
+// executeCommandWithConfig runs the CLI like executeCommand, with the given configuration file content (if any).
+func executeCommandWithConfig(t *testing.T, cfg string, args ...string) (string, string, error) {
+	resetFlags(rootCmd)
+	var cfgFile = filepath.Join(t.TempDir(), "config.yaml")
+	if cfg != "" {
+		assert.NoError(t, os.WriteFile(cfgFile, []byte(cfg), 0600))
+	}
+	t.Setenv("SCORE_HUMANITEC_CONFIG", cfgFile)
+	for _, setting := range configSettings {
+		t.Setenv(setting.EnvVar, "")
+	}
+
+	var stdout, stderr bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetErr(&stderr)
+	rootCmd.SetArgs(args)
+	err := rootCmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+// arrayFlags binds the string array flags to their variables.
+// Array values keep track of being set internally, so they are replaced instead of being reset.
+var arrayFlags = map[string]*[]string{
+	"file":                 &scoreFiles,
+	"property":             &overrideParams,
+	"trace-secret-pattern": &traceSecretPatterns,
+}
+
+// resetFlags restores the default values of the command flags and its sub-commands flags.
+// Flags share the same variables across commands, so the values would leak between the test runs otherwise.
+func resetFlags(cmd *cobra.Command) {
+	var reset = func(f *pflag.Flag) {
+		if ptr, ok := arrayFlags[f.Name]; ok && f.Value.Type() == "stringArray" {
+			var values = []string{}
+			if def := strings.Trim(f.DefValue, "[]"); def != "" {
+				values = strings.Split(def, ",")
+			}
+			var fs = pflag.NewFlagSet(f.Name, pflag.ContinueOnError)
+			fs.StringArrayVar(ptr, f.Name, values, f.Usage)
+			f.Value = fs.Lookup(f.Name).Value
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// writeFile writes the test file into the directory and returns the file path.
+func writeFile(t *testing.T, dir, name, content string) string {
+	var path = filepath.Join(dir, name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
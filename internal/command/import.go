@@ -0,0 +1,149 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/score-spec/score-humanitec/internal/humanitec"
+	api "github.com/score-spec/score-humanitec/internal/humanitec_go/client"
+)
+
+func init() {
+	importCmd.Flags().StringVar(&apiUrl, "api-url", apiUrlDefault, "Humanitec API endpoint")
+	importCmd.Flags().StringVar(&apiToken, "token", "", "Humanitec API authentication token")
+	importCmd.MarkFlagRequired("token")
+	importCmd.Flags().StringVar(&orgID, "org", "", "Organization ID")
+	importCmd.MarkFlagRequired("org")
+	importCmd.Flags().StringVar(&appID, "app", "", "Application ID")
+	importCmd.MarkFlagRequired("app")
+	importCmd.Flags().StringVar(&envID, "env", "", "Environment ID")
+	importCmd.MarkFlagRequired("env")
+	importCmd.Flags().StringVar(&moduleID, "module", "", "The ID of the deployed module (workload) to import")
+	importCmd.MarkFlagRequired("module")
+
+	importCmd.Flags().StringVarP(&importScoreFile, "file", "f", scoreFileDefault, "Target SCORE file")
+	importCmd.Flags().StringVar(&extensionsFile, "extensions", extensionsFileDefault, "Target extensions file, written only if needed")
+	importCmd.Flags().BoolVar(&overwrite, "overwrite", false, "Overwrite the target files if they exist")
+
+	importCmd.Flags().IntVar(&retryMax, "retry-max", retryMaxDefault, "Maximum number of retries for transient Humanitec API errors (0 disables retries)")
+	importCmd.Flags().DurationVar(&retryTimeout, "retry-timeout", retryTimeoutDefault, "Maximum time to spend retrying a Humanitec API call")
+	addTransportFlags(importCmd.Flags())
+	importCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable diagnostic messages (written to STDERR)")
+
+	rootCmd.AddCommand(importCmd)
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Generates a SCORE file from a module deployed in a Humanitec environment",
+	Long: `This command fetches the module specified by the --module flag from the last deployment of the Humanitec
+environment specified by the --org, --app, and --env flags, and writes an equivalent SCORE file. The module features
+SCORE can not express (e.g. the workload profile, or containers security context) are written to the extensions file.
+
+The '${externals...}', '${shared...}', '${modules...}' and '${values...}' placeholders are replaced with references
+to the matching SCORE resources. The module fields that can not be converted, or only approximately, are listed on
+STDERR once the files are written.
+`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         importModule,
+}
+
+func importModule(cmd *cobra.Command, args []string) error {
+	setupLogging()
+
+	if err := validateIDs(); err != nil {
+		return err
+	}
+	if err := checkOverwrite(importScoreFile); err != nil {
+		return err
+	}
+
+	client, err := newApiClient()
+	if err != nil {
+		return err
+	}
+
+	// Fetch the deployed module
+	//
+	log.Printf("Fetching the deployment set of environment '%s'...\n", envID)
+	set, err := api.GetDeployedSet(cmd.Context(), client, orgID, appID, envID)
+	if err != nil {
+		return err
+	}
+	module, exists := set.Modules[moduleID]
+	if !exists {
+		return fmt.Errorf("module '%s' is not deployed in environment '%s'", moduleID, envID)
+	}
+
+	// Convert the module
+	//
+	log.Printf("Converting module '%s'...\n", moduleID)
+	res, err := humanitec.ImportModule(moduleID, module, set.Shared)
+	if err != nil {
+		return fmt.Errorf("importing module '%s': %w", moduleID, err)
+	}
+
+	// Write the SCORE and extensions files
+	//
+	if res.Extensions != nil {
+		if err := checkOverwrite(extensionsFile); err != nil {
+			return err
+		}
+	}
+	log.Printf("Writing '%s'...\n", importScoreFile)
+	if err := writeYAML(importScoreFile, res.Workload); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Module '%s' written to '%s'\n", moduleID, importScoreFile)
+	if res.Extensions != nil {
+		log.Printf("Writing '%s'...\n", extensionsFile)
+		if err := writeYAML(extensionsFile, res.Extensions); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Extensions written to '%s'\n", extensionsFile)
+	}
+
+	if len(res.Lossy) > 0 {
+		fmt.Fprintln(cmd.ErrOrStderr(), "The following module fields could not be fully converted:")
+		for _, field := range res.Lossy {
+			fmt.Fprintf(cmd.ErrOrStderr(), "  - %s\n", field)
+		}
+	}
+
+	return nil
+}
+
+// checkOverwrite returns an error if the file exists, unless --overwrite is set.
+func checkOverwrite(file string) error {
+	if _, err := os.Stat(file); err == nil && !overwrite {
+		return fmt.Errorf("file '%s' already exists, use --overwrite to replace it", file)
+	}
+	return nil
+}
+
+// writeYAML writes the value into the file as YAML, indented with 2 spaces.
+func writeYAML(file string, val interface{}) error {
+	var buf bytes.Buffer
+	var enc = yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(val); err != nil {
+		return fmt.Errorf("marshalling '%s': %w", file, err)
+	}
+	if err := os.WriteFile(file, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("writing '%s': %w", file, err)
+	}
+	return nil
+}
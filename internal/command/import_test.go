@@ -0,0 +1,102 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package command
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ht "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+func TestImport(t *testing.T) {
+	var srv = newFakeServer(t)
+	var dir = t.TempDir()
+	var scoreFile = writeFile(t, dir, "score.yaml", testScoreFile)
+	var extFile = writeFile(t, dir, "humanitec.score.yaml", `apiVersion: humanitec.org/v1b1
+profile: humanitec/worker
+`)
+
+	_, _, err := executeCommand(t, deltaArgs(srv, scoreFile, "--extensions", extFile, "--deploy")...)
+	assert.NoError(t, err)
+
+	var importedFile = filepath.Join(dir, "imported.score.yaml")
+	var importedExtFile = filepath.Join(dir, "imported.humanitec.score.yaml")
+	var args = []string{
+		"import", "--module", "web", "-f", importedFile, "--extensions", importedExtFile,
+		"--api-url", srv.URL, "--token", srv.Token,
+		"--org", testOrgID, "--app", testAppID, "--env", testEnvID,
+	}
+	_, stderr, err := executeCommand(t, args...)
+	assert.NoError(t, err)
+	assert.Contains(t, stderr, "Module 'web' written to")
+	assert.NotContains(t, stderr, "could not be fully converted")
+
+	raw, err := os.ReadFile(importedExtFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "apiVersion: humanitec.org/v1b1\nprofile: humanitec/worker\n", string(raw))
+
+	// The imported files must produce the same module
+	stdout, _, err := executeCommand(t, "run", "-f", importedFile, "--extensions", importedExtFile, "--env", testEnvID)
+	assert.NoError(t, err)
+	var delta ht.CreateDeploymentDeltaRequest
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &delta))
+	var expected, _ = json.Marshal(srv.DeployedSet(testOrgID, testAppID, testEnvID).Modules["web"])
+	var actual, _ = json.Marshal(delta.Modules.Add["web"])
+	assert.JSONEq(t, string(expected), string(actual))
+
+	// Existing files are not overwritten by default
+	_, _, err = executeCommand(t, args...)
+	assert.ErrorContains(t, err, "already exists, use --overwrite")
+	_, _, err = executeCommand(t, append(args, "--overwrite")...)
+	assert.NoError(t, err)
+
+	// Unknown modules
+	args[2] = "unknown"
+	_, _, err = executeCommand(t, append(args, "--overwrite")...)
+	assert.EqualError(t, err, "module 'unknown' is not deployed in environment 'development'")
+}
+
+func TestImport_existingExtensions(t *testing.T) {
+	var srv = newFakeServer(t)
+	var dir = t.TempDir()
+	var scoreFile = writeFile(t, dir, "score.yaml", testScoreFile)
+	var extContent = "apiVersion: humanitec.org/v1b1\nprofile: humanitec/worker\n"
+	var extFile = writeFile(t, dir, "humanitec.score.yaml", extContent)
+
+	_, _, err := executeCommand(t, deltaArgs(srv, scoreFile, "--deploy")...)
+	assert.NoError(t, err)
+
+	// The existing extensions file is kept if the module has no extensions
+	var importedFile = filepath.Join(dir, "imported.score.yaml")
+	var args = []string{
+		"import", "--module", "web", "-f", importedFile, "--extensions", extFile,
+		"--api-url", srv.URL, "--token", srv.Token,
+		"--org", testOrgID, "--app", testAppID, "--env", testEnvID,
+	}
+	_, stderr, err := executeCommand(t, args...)
+	assert.NoError(t, err)
+	assert.Contains(t, stderr, "Module 'web' written to")
+	assert.NotContains(t, stderr, "Extensions written to")
+	raw, err := os.ReadFile(extFile)
+	assert.NoError(t, err)
+	assert.Equal(t, extContent, string(raw))
+
+	// The existing extensions file is not overwritten by the module extensions
+	_, _, err = executeCommand(t, deltaArgs(srv, scoreFile, "--extensions", extFile, "--deploy")...)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(importedFile))
+	_, _, err = executeCommand(t, args...)
+	assert.EqualError(t, err, "file '"+extFile+"' already exists, use --overwrite to replace it")
+	_, err = os.Stat(importedFile)
+	assert.True(t, os.IsNotExist(err))
+}
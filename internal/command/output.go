@@ -0,0 +1,170 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/score-spec/score-humanitec/internal/humanitec"
+	ht "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+const (
+	formatYAML    = "yaml"
+	formatCompact = "compact"
+
+	// deltaResultApiVersion is the version of the 'delta' command result format.
+	// It must be changed on any backward incompatible change to the deltaResult structure.
+	deltaResultApiVersion = "score-humanitec/v1"
+	deltaResultKind       = "DeltaResult"
+)
+
+var (
+	resultFormat string
+	outputFile   string
+)
+
+// deltaResult is the result of the 'delta' command, for CI steps to consume.
+type deltaResult struct {
+	ApiVersion string                  `json:"apiVersion"`
+	Kind       string                  `json:"kind"`
+	Delta      *ht.DeploymentDelta     `json:"delta"`
+	Url        string                  `json:"url"`
+	Deployment *deploymentResult       `json:"deployment,omitempty"`
+	Pruned     *humanitec.PruneSummary `json:"pruned,omitempty"`
+	Warnings   []string                `json:"warnings"`
+}
+
+// deploymentResult describes the deployment triggered by the 'delta --deploy' command.
+type deploymentResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// addOutputFlags registers the flags controlling the command result format and destination.
+func addOutputFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&resultFormat, "format", formatJSON, "Output format: json, yaml or compact (single line JSON)")
+	flags.StringVarP(&outputFile, "output", "o", "", "Write the output to the file instead of STDOUT")
+}
+
+func validateResultFormat() error {
+	switch resultFormat {
+	case formatJSON, formatYAML, formatCompact:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format '%s', expected '%s', '%s' or '%s'", resultFormat, formatJSON, formatYAML, formatCompact)
+	}
+}
+
+// writeResult writes the value in the --format format to the --output file, or to the out writer (STDOUT) if not set.
+func writeResult(out io.Writer, val interface{}) error {
+	data, err := marshalOutput(resultFormat, val)
+	if err != nil {
+		return err
+	}
+	if outputFile != "" {
+		if err := os.WriteFile(outputFile, data, 0644); err != nil {
+			return fmt.Errorf("writing output file '%s': %w", outputFile, err)
+		}
+		return nil
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// writeFormatted writes the value in the json, yaml or compact format to the out writer.
+func writeFormatted(out io.Writer, format string, val interface{}) error {
+	data, err := marshalOutput(format, val)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// marshalOutput returns the value in the json, yaml or compact format, ending with a new line.
+func marshalOutput(format string, val interface{}) ([]byte, error) {
+	var data []byte
+	var err error
+	switch format {
+	case formatCompact:
+		data, err = json.Marshal(val)
+	case formatYAML:
+		// Go through JSON, so that the field names match the API and the JSON output
+		var obj interface{}
+		if data, err = json.Marshal(val); err == nil {
+			if err = json.Unmarshal(data, &obj); err == nil {
+				data, err = yaml.Marshal(obj)
+			}
+		}
+	default:
+		data, err = json.MarshalIndent(val, "", "  ")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("marshalling output: %w", err)
+	}
+	if !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data, '\n')
+	}
+	return data, nil
+}
+
+// setupLogging writes the diagnostic messages to STDERR if --verbose is set, and discards them otherwise.
+func setupLogging() {
+	if verbose {
+		log.SetOutput(os.Stderr)
+	} else {
+		log.SetOutput(io.Discard)
+	}
+}
+
+// validateFormat checks the --format flag value of the commands that output a table by default.
+// The other values are the same as for the commands result (see validateResultFormat).
+func validateFormat() error {
+	switch outputFormat {
+	case formatTable, formatJSON, formatYAML, formatCompact:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format '%s', expected '%s', '%s', '%s' or '%s'", outputFormat, formatTable, formatJSON, formatYAML, formatCompact)
+	}
+}
+
+// writeJSON writes the value as indented JSON to the out writer.
+func writeJSON(out io.Writer, val interface{}) error {
+	tmp, err := json.MarshalIndent(val, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(tmp))
+	return err
+}
+
+// printDeltaMetadata writes a summary of the deployment delta, as a table.
+func printDeltaMetadata(out io.Writer, delta *ht.DeploymentDelta) {
+	var w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%s\n", delta.ID)
+	fmt.Fprintf(w, "Name:\t%s\n", delta.Metadata.Name)
+	fmt.Fprintf(w, "Environment:\t%s\n", delta.Metadata.EnvID)
+	fmt.Fprintf(w, "Archived:\t%t\n", delta.Metadata.Archived)
+	fmt.Fprintf(w, "Contributors:\t%s\n", strings.Join(delta.Metadata.Contributers, ", "))
+	fmt.Fprintf(w, "Created:\t%s by %s\n", delta.Metadata.CreatedAt, delta.Metadata.CreatedBy)
+	fmt.Fprintf(w, "Modified:\t%s\n", delta.Metadata.ModifiedAt)
+	fmt.Fprintf(w, "Modules:\t%d added, %d updated, %d removed\n", len(delta.Modules.Add), len(delta.Modules.Update), len(delta.Modules.Remove))
+	fmt.Fprintf(w, "Shared:\t%d changes\n", len(delta.Shared))
+	w.Flush()
+}
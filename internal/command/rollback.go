@@ -0,0 +1,115 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package command
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	api "github.com/score-spec/score-humanitec/internal/humanitec_go/client"
+	ht "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+func init() {
+	rollbackCmd.Flags().StringVar(&apiUrl, "api-url", apiUrlDefault, "Humanitec API endpoint")
+	rollbackCmd.Flags().StringVar(&apiToken, "token", "", "Humanitec API authentication token")
+	rollbackCmd.MarkFlagRequired("token")
+	rollbackCmd.Flags().StringVar(&orgID, "org", "", "Organization ID")
+	rollbackCmd.MarkFlagRequired("org")
+	rollbackCmd.Flags().StringVar(&appID, "app", "", "Application ID")
+	rollbackCmd.MarkFlagRequired("app")
+	rollbackCmd.Flags().StringVar(&envID, "env", "", "Environment ID")
+	rollbackCmd.MarkFlagRequired("env")
+
+	rollbackCmd.Flags().StringVar(&rollbackTo, "to", "", "The ID of the deployment to roll back to (defaults to the previous successful deployment)")
+	rollbackCmd.Flags().BoolVar(&retry, "retry", false, "Retry deployments when a deployment is currently in progress (limited by --retry-max and --retry-timeout)")
+	rollbackCmd.Flags().IntVar(&retryMax, "retry-max", retryMaxDefault, "Maximum number of retries for transient Humanitec API errors (0 disables retries)")
+	rollbackCmd.Flags().DurationVar(&retryTimeout, "retry-timeout", retryTimeoutDefault, "Maximum time to spend retrying a Humanitec API call")
+	addTransportFlags(rollbackCmd.Flags())
+	rollbackCmd.Flags().BoolVar(&wait, "wait", false, "Wait for the triggered deployment to complete")
+	rollbackCmd.Flags().DurationVar(&waitTimeout, "timeout", waitTimeoutDefault, "Maximum time to wait for the deployment to complete")
+	rollbackCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable diagnostic messages (written to STDERR)")
+
+	rootCmd.AddCommand(rollbackCmd)
+}
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Redeploys a previous deployment of the Humanitec environment",
+	Long: `This command redeploys the deployment set of a previous deployment in the Humanitec environment specified by
+the --org, --app, and --env flags. By default, the last successful deployment before the current one is used,
+the --to flag allows to select a specific deployment instead.
+`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         rollback,
+}
+
+func rollback(cmd *cobra.Command, args []string) error {
+	setupLogging()
+
+	if err := validateIDs(); err != nil {
+		return err
+	}
+
+	client, err := newApiClient()
+	if err != nil {
+		return err
+	}
+
+	// Find the deployment to roll back to
+	//
+	var target *ht.Deployment
+	if rollbackTo != "" {
+		log.Printf("Fetching deployment '%s'...\n", rollbackTo)
+		if target, err = client.GetDeployment(cmd.Context(), orgID, appID, envID, rollbackTo); err != nil {
+			return err
+		}
+		if target.Status != ht.DeploymentStatusSucceeded {
+			log.Printf("Warning: deployment '%s' status is '%s'\n", target.ID, target.Status)
+		}
+	} else {
+		log.Print("Listing deployments...\n")
+		deployments, err := client.ListDeployments(cmd.Context(), orgID, appID, envID)
+		if err != nil {
+			return err
+		}
+		if target, err = api.PreviousSuccessfulDeployment(deployments); err != nil {
+			return fmt.Errorf("finding deployment to roll back to in environment '%s': %w", envID, err)
+		}
+	}
+	if target.SetID == "" {
+		return fmt.Errorf("deployment '%s' has no deployment set", target.ID)
+	}
+
+	// Redeploy the deployment set
+	//
+	log.Printf("Redeploying deployment '%s' (set '%s')...\n", target.ID, target.SetID)
+	deployment, err := client.StartDeployment(cmd.Context(), orgID, appID, envID, retry, &ht.StartDeploymentRequest{
+		SetID:   target.SetID,
+		Comment: fmt.Sprintf("Rollback to deployment '%s' (SCORE)", target.ID),
+	})
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(cmd.OutOrStdout(), deployment); err != nil {
+		return err
+	}
+
+	// Wait for the deployment to complete (optional)
+	//
+	if wait {
+		if _, err := waitForDeployment(cmd.Context(), cmd.ErrOrStderr(), client, deployment.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -9,9 +9,11 @@ package command
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
+	"github.com/score-spec/score-humanitec/internal/config"
 	"github.com/score-spec/score-humanitec/internal/version"
 )
 
@@ -23,14 +25,97 @@ var (
 This tool creates a Humanitec deployment from the SCORE specification.
 Complete documentation is available at https://score.dev`,
 		Version: fmt.Sprintf("%s (build: %s; sha: %s)", version.Version, version.BuildTime, version.GitSHA),
+
+		PersistentPreRunE: applyConfig,
 	}
 )
 
+// configSettings maps the command flags that can be defaulted from the environment variables
+// and the configuration file contexts. They only apply to the commands calling the Humanitec API.
+var configSettings = []struct {
+	Flag   string
+	EnvVar string
+	Value  func(*config.Context) string
+}{
+	{"token", "HUMANITEC_TOKEN", func(c *config.Context) string { return c.Token }},
+	{"org", "HUMANITEC_ORG", func(c *config.Context) string { return c.Org }},
+	{"app", "HUMANITEC_APP", func(c *config.Context) string { return c.App }},
+	{"env", "HUMANITEC_ENV", func(c *config.Context) string { return c.Env }},
+	{"api-url", "HUMANITEC_API_URL", func(c *config.Context) string { return c.ApiUrl }},
+	{"ui-url", "HUMANITEC_UI_URL", func(c *config.Context) string { return c.UiUrl }},
+	{"request-timeout", "HUMANITEC_REQUEST_TIMEOUT", func(c *config.Context) string { return c.RequestTimeout }},
+	{"ca-file", "HUMANITEC_CA_FILE", func(c *config.Context) string { return c.CAFile }},
+	{"client-cert", "HUMANITEC_CLIENT_CERT", func(c *config.Context) string { return c.ClientCert }},
+	{"client-key", "HUMANITEC_CLIENT_KEY", func(c *config.Context) string { return c.ClientKey }},
+	{"proxy", "HUMANITEC_PROXY", func(c *config.Context) string { return c.Proxy }},
+}
+
+// filterFlagAnnotation marks the optional filter flags, which are never defaulted from the configuration,
+// e.g. 'deltas list --env'.
+const filterFlagAnnotation = "score-humanitec/filter"
+
 func init() {
+	rootCmd.PersistentFlags().StringVar(&contextName, "context", "", "Configuration context to use (defaults to the current context)")
+
 	rootCmd.SetVersionTemplate(`{{with .Name}}{{printf "%s " .}}{{end}}{{printf "%s" .Version}}
 `)
 }
 
+// applyConfig sets the command flags that were not explicitly provided on the command line.
+// Environment variables take precedence over the values from the selected configuration context.
+// Flags are set through the flag set, so that required flags checks are satisfied.
+// Only the commands calling the Humanitec API (with a '--token' flag) are configured, and the filter flags are skipped.
+func applyConfig(cmd *cobra.Command, args []string) error {
+	if cmd.Flags().Lookup("token") == nil {
+		return nil
+	}
+
+	var ctx *config.Context
+	if contextName != "" {
+		// Fail on an unknown context even if all the values are provided otherwise
+		var err error
+		if ctx, err = loadContext(contextName); err != nil {
+			return err
+		}
+	}
+	for _, setting := range configSettings {
+		var flag = cmd.Flags().Lookup(setting.Flag)
+		if flag == nil || flag.Changed || flag.Annotations[filterFlagAnnotation] != nil {
+			continue
+		}
+
+		var value = os.Getenv(setting.EnvVar)
+		if value == "" {
+			if ctx == nil {
+				var err error
+				if ctx, err = loadContext(contextName); err != nil {
+					return err
+				}
+			}
+			value = setting.Value(ctx)
+		}
+		if value != "" {
+			if err := cmd.Flags().Set(setting.Flag, value); err != nil {
+				return fmt.Errorf("setting '--%s' from config: %w", setting.Flag, err)
+			}
+		}
+	}
+	return nil
+}
+
+// loadContext reads the named context from the configuration file.
+func loadContext(name string) (*config.Context, error) {
+	path, err := config.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Context(name)
+}
+
 func Execute() error {
 	return rootCmd.Execute()
 }
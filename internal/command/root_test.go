@@ -0,0 +1,68 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ht "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+func TestApplyConfig_offlineCommands(t *testing.T) {
+	var scoreFile = writeFile(t, t.TempDir(), "score.yaml", testScoreFile)
+
+	// The configuration is not read by the commands that do not call the API
+	_, _, err := executeCommandWithConfig(t, "{NOT A VALID YAML", "run", "-f", scoreFile)
+	assert.EqualError(t, err, `required flag(s) "env" not set`)
+
+	stdout, _, err := executeCommandWithConfig(t, "{NOT A VALID YAML", "run", "-f", scoreFile, "--env", testEnvID)
+	assert.NoError(t, err)
+	var delta ht.CreateDeploymentDeltaRequest
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &delta))
+	assert.Equal(t, testEnvID, delta.Metadata.EnvID)
+
+	_, _, err = executeCommandWithConfig(t, "{NOT A VALID YAML", "deltas", "list")
+	assert.Error(t, err)
+}
+
+func TestApplyConfig_filterFlags(t *testing.T) {
+	var srv = newFakeServer(t)
+	srv.AddEnvironment(testOrgID, testAppID, ht.Environment{ID: "staging", Type: "development"})
+	var scoreFile = writeFile(t, t.TempDir(), "score.yaml", testScoreFile)
+	_, _, err := executeCommand(t, deltaArgs(srv, scoreFile)...)
+	assert.NoError(t, err)
+	_, _, err = executeCommand(t, deltaArgs(srv, scoreFile, "--env", "staging")...)
+	assert.NoError(t, err)
+
+	var cfg = fmt.Sprintf(`current-context: test
+contexts:
+  test:
+    api-url: %s
+    token: %s
+    org: %s
+    app: %s
+    env: staging
+`, srv.URL, srv.Token, testOrgID, testAppID)
+
+	// The context environment is not used as a filter
+	stdout, _, err := executeCommandWithConfig(t, cfg, "deltas", "list", "--format", "json")
+	assert.NoError(t, err)
+	var deltas []ht.DeploymentDelta
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &deltas))
+	assert.Len(t, deltas, 2)
+
+	stdout, _, err = executeCommandWithConfig(t, cfg, "deltas", "list", "--format", "json", "--env", "staging")
+	assert.NoError(t, err)
+	deltas = nil
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &deltas))
+	assert.Len(t, deltas, 1)
+}
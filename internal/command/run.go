@@ -8,34 +8,23 @@ The Apache Software Foundation (http://www.apache.org/).
 package command
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
 
-	"github.com/imdario/mergo"
-	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/cobra"
-	"github.com/tidwall/sjson"
 
 	"github.com/score-spec/score-humanitec/internal/humanitec"
-	"github.com/score-spec/score-humanitec/internal/humanitec/extensions"
-
-	yaml "gopkg.in/yaml.v3"
-
-	loader "github.com/score-spec/score-go/loader"
-	schema "github.com/score-spec/score-go/schema"
-	score "github.com/score-spec/score-go/types"
 )
 
 func init() {
-	runCmd.Flags().StringVarP(&scoreFile, "file", "f", scoreFileDefault, "Source SCORE file")
+	runCmd.Flags().StringArrayVarP(&scoreFiles, "file", "f", []string{scoreFileDefault}, "Source SCORE file (can be repeated or use a glob pattern)")
 	runCmd.Flags().StringVar(&overridesFile, "overrides", overridesFileDefault, "Overrides file")
 	runCmd.Flags().StringVar(&extensionsFile, "extensions", extensionsFileDefault, "Extensions file")
 	runCmd.Flags().StringVar(&workloadSourceURL, "workload-source-url", "", "URL of file that is managing the humanitec workload")
+	runCmd.Flags().StringVar(&resourceTypesFile, "resource-types-file", "", "Cached JSON list of the organization resource types to validate resources against")
 	runCmd.Flags().StringVar(&envID, "env", "", "Environment ID")
 	runCmd.MarkFlagRequired("env")
 
@@ -43,8 +32,11 @@ func init() {
 	runCmd.Flags().StringVarP(&currentImage, "image", "i", ".", "Image to use for the current image, signified by \".\"")
 	runCmd.Flags().StringVarP(&message, "message", "m", messageDefault, "Message")
 
+	runCmd.Flags().BoolVar(&validateOutput, "validate-output", false, "Validate the generated deployment delta against the embedded JSON schema")
+	runCmd.Flags().BoolVar(&strict, "strict", false, "Fail if any '${...}' reference can not be resolved")
 	runCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "DEPRECATED: Disables Score file schema validation.")
 	runCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable diagnostic messages (written to STDERR)")
+	addOutputFlags(runCmd.Flags())
 
 	rootCmd.AddCommand(runCmd)
 }
@@ -59,167 +51,146 @@ func run(cmd *cobra.Command, args []string) error {
 	if !verbose {
 		log.SetOutput(io.Discard)
 	}
+	if err := validateResultFormat(); err != nil {
+		return err
+	}
 
-	// Load SCORE spec and extensions
+	// Load SCORE specs and extensions
 	//
-	baseDir := filepath.Dir(scoreFile)
-	spec, ext, err := loadSpec(scoreFile, overridesFile, extensionsFile, skipValidation)
+	workloads, err := loadWorkloads(scoreFiles, overridesFile, extensionsFile, skipValidation)
 	if err != nil {
 		return err
 	}
 
+	// Validate resources against the cached organization resource types (optional)
+	//
+	if resourceTypesFile != "" {
+		if err := validateResources(cmd.Context(), nil, workloads); err != nil {
+			return err
+		}
+	}
+
 	// Prepare a new deployment
 	//
 	log.Print("Preparing a new deployment...\n")
-	delta, err := humanitec.ConvertSpec(message, envID, baseDir, workloadSourceURL, spec, ext)
+	delta, warnings, err := humanitec.ConvertSpecs(message, envID, workloadSourceURL, workloads, strict)
 	if err != nil {
 		return fmt.Errorf("preparing new deployment: %w", err)
 	}
+	logWarnings(warnings)
+	if validateOutput {
+		log.Print("Validating deployment delta...\n")
+		if err := humanitec.ValidateDelta(delta); err != nil {
+			return fmt.Errorf("validating deployment delta: %w", err)
+		}
+	}
 
 	// Output resulting deployment delta
 	//
-	tmp, err := json.MarshalIndent(&delta, "", "  ")
-	if err != nil {
-		return err
-	}
-	os.Stdout.Write(tmp)
-
-	return nil
+	return writeResult(cmd.OutOrStdout(), delta)
 }
 
-func loadSpec(scoreFile, overridesFile, extensionsFile string, skipValidation bool) (*score.Workload, *extensions.HumanitecExtensionsSpec, error) {
-	// Open source file
-	//
-	log.Printf("Reading '%s'...\n", scoreFile)
-	var err error
-	var src *os.File
-	if src, err = os.Open(scoreFile); err != nil {
-		return nil, nil, err
-	}
-
-	// Parse SCORE spec
-	//
-	log.Print("Parsing SCORE spec...\n")
-	var srcMap map[string]interface{}
-	if err := loader.ParseYAML(&srcMap, src); err != nil {
-		return nil, nil, err
+// loadWorkloads loads all SCORE specs matching the given file names or glob patterns.
+// When more than one SCORE file is used, the default overrides and extensions files are looked up
+// next to each SCORE file instead of the current directory.
+func loadWorkloads(patterns []string, overridesFile, extensionsFile string, skipValidation bool) ([]humanitec.WorkloadSource, error) {
+	var files = make([]string, 0, len(patterns))
+	var seen = make(map[string]bool)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file pattern '%s': %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			// Not a pattern or no matches, loadSpec will report a missing file
+			matches = []string{pattern}
+		}
+		for _, file := range matches {
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
 	}
 
-	// Apply overrides from file (optional)
-	//
-	if overridesFile != "" {
-		log.Printf("Checking '%s'...\n", overridesFile)
-		if ovr, err := os.Open(overridesFile); err == nil {
-			defer ovr.Close()
-
-			log.Print("Applying SCORE overrides...\n")
-			var ovrMap map[string]interface{}
-			if err := loader.ParseYAML(&ovrMap, ovr); err != nil {
-				return nil, nil, err
-			}
-			if err := mergo.MergeWithOverwrite(&srcMap, ovrMap); err != nil {
-				return nil, nil, fmt.Errorf("applying overrides fom '%s': %w", overridesFile, err)
-			}
-		} else if !os.IsNotExist(err) || overridesFile != overridesFileDefault {
-			return nil, nil, err
+	if len(files) > 1 {
+		if overridesFile != overridesFileDefault {
+			return nil, fmt.Errorf("the --overrides flag can only be used with a single SCORE file")
+		}
+		if extensionsFile != extensionsFileDefault {
+			return nil, fmt.Errorf("the --extensions flag can only be used with a single SCORE file")
 		}
 	}
 
-	// Apply overrides from command line (optional)
-	//
-	for _, pstr := range overrideParams {
-		log.Print("Applying SCORE properties overrides...\n")
+	var workloads = make([]humanitec.WorkloadSource, 0, len(files))
+	for _, file := range files {
+		var baseDir = filepath.Dir(file)
+		var ovrFile, extFile = overridesFile, extensionsFile
+		if len(files) > 1 {
+			ovrFile = siblingFile(baseDir, overridesFileDefault)
+			extFile = siblingFile(baseDir, extensionsFileDefault)
+		}
 
-		jsonBytes, err := json.Marshal(srcMap)
+		workload, err := loadSpec(file, ovrFile, extFile, skipValidation)
 		if err != nil {
-			return nil, nil, fmt.Errorf("marshalling score spec: %w", err)
+			return nil, fmt.Errorf("loading '%s': %w", file, err)
 		}
+		workloads = append(workloads, *workload)
+	}
 
-		pmap := strings.SplitN(pstr, "=", 2)
-		if len(pmap) <= 1 {
-			var path = pmap[0]
-			log.Printf("removing '%s'", path)
-			if jsonBytes, err = sjson.DeleteBytes(jsonBytes, path); err != nil {
-				return nil, nil, fmt.Errorf("removing '%s': %w", path, err)
-			}
-		} else {
-			var path = pmap[0]
-			var val interface{}
-			if err := yaml.Unmarshal([]byte(pmap[1]), &val); err != nil {
-				val = pmap[1]
-			}
-
-			log.Printf("overriding '%s' = '%s'", path, val)
-			if jsonBytes, err = sjson.SetBytes(jsonBytes, path, val); err != nil {
-				return nil, nil, fmt.Errorf("overriding '%s': %w", path, err)
-			}
-		}
+	return workloads, nil
+}
 
-		if err = json.Unmarshal(jsonBytes, &srcMap); err != nil {
-			return nil, nil, fmt.Errorf("unmarshalling score spec: %w", err)
-		}
+// siblingFile returns the path to the default file in the baseDir, or an empty string if it does not exist.
+func siblingFile(baseDir, defaultFile string) string {
+	var path = filepath.Join(baseDir, filepath.Base(defaultFile))
+	if _, err := os.Stat(path); err != nil {
+		return ""
 	}
+	return path
+}
 
-	// Replace images defined by . (optional)
-	//
-	if containers, ok := srcMap["containers"].(map[string]interface{}); ok {
-		for _, containerVal := range containers {
-			if container, ok := containerVal.(map[string]interface{}); ok {
-				if image, ok := container["image"].(string); ok && image == "." {
-					container["image"] = currentImage
-				}
-			}
-		}
+func loadSpec(scoreFile, overridesFile, extensionsFile string, skipValidation bool) (*humanitec.WorkloadSource, error) {
+	var opts = humanitec.LoadOptions{
+		BaseDir:        filepath.Dir(scoreFile),
+		Properties:     overrideParams,
+		Image:          currentImage,
+		SkipValidation: skipValidation,
+		Logger:         log.Default(),
 	}
 
-	// Load extensions (optional)
+	// Read source file
 	//
-	var extMap = make(map[string]interface{})
-	if extensionsFile != "" {
-		log.Printf("Checking '%s'...\n", extensionsFile)
-		if extFile, err := os.Open(extensionsFile); err == nil {
-			defer extFile.Close()
-
-			log.Print("Loading SCORE extensions...\n")
-			if err := yaml.NewDecoder(extFile).Decode(extMap); err != nil {
-				return nil, nil, fmt.Errorf("parsing extensions file '%s': %w", extensionsFile, err)
-			}
-		} else if !os.IsNotExist(err) || extensionsFile != extensionsFileDefault {
-			return nil, nil, err
-		}
+	log.Printf("Reading '%s'...\n", scoreFile)
+	var err error
+	if opts.Spec, err = os.ReadFile(scoreFile); err != nil {
+		return nil, err
 	}
 
-	// Apply upgrades to fix backports or backward incompatible things
-	if changes, err := schema.ApplyCommonUpgradeTransforms(srcMap); err != nil {
-		return nil, nil, fmt.Errorf("failed to upgrade spec: %w", err)
-	} else if len(changes) > 0 {
-		for _, change := range changes {
-			log.Printf("Applying upgrade to specification: %s\n", change)
+	// Read overrides file (optional)
+	//
+	if overridesFile != "" {
+		log.Printf("Checking '%s'...\n", overridesFile)
+		if opts.Overrides, err = os.ReadFile(overridesFile); err != nil && (!os.IsNotExist(err) || overridesFile != overridesFileDefault) {
+			return nil, err
 		}
 	}
 
-	// Validate SCORE spec
+	// Read extensions file (optional)
 	//
-	if !skipValidation {
-		log.Print("Validating SCORE spec...\n")
-		if err := schema.Validate(srcMap); err != nil {
-			return nil, nil, fmt.Errorf("validating workload spec: %w", err)
+	if extensionsFile != "" {
+		log.Printf("Checking '%s'...\n", extensionsFile)
+		if opts.Extensions, err = os.ReadFile(extensionsFile); err != nil && (!os.IsNotExist(err) || extensionsFile != extensionsFileDefault) {
+			return nil, err
 		}
 	}
 
-	// Convert SCORE spec
-	//
-
-	var spec score.Workload
-	log.Print("Applying SCORE spec...\n")
-	if err = mapstructure.Decode(srcMap, &spec); err != nil {
-		return nil, nil, fmt.Errorf("applying workload spec: %w", err)
-	}
+	return humanitec.LoadWorkload(opts)
+}
 
-	var ext extensions.HumanitecExtensionsSpec
-	if err = mapstructure.Decode(extMap, &ext); err != nil {
-		return nil, nil, fmt.Errorf("applying extensions spec: %w", err)
+// logWarnings reports the conversion warnings to the diagnostic messages log.
+func logWarnings(warnings []humanitec.Warning) {
+	for _, warning := range warnings {
+		log.Printf("Warning: %s\n", warning)
 	}
-
-	return &spec, &ext, nil
 }
@@ -0,0 +1,89 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package command
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v3"
+
+	ht "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+const testScoreFile = `apiVersion: score.dev/v1b1
+metadata:
+  name: web
+containers:
+  main:
+    image: nginx
+    variables:
+      DB_HOST: ${resources.db.host}
+resources:
+  db:
+    type: postgres
+`
+
+func TestRun(t *testing.T) {
+	var dir = t.TempDir()
+	var scoreFile = writeFile(t, dir, "score.yaml", testScoreFile)
+
+	stdout, _, err := executeCommand(t, "run", "-f", scoreFile, "--env", "development")
+	assert.NoError(t, err)
+
+	var delta ht.CreateDeploymentDeltaRequest
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &delta))
+	assert.Equal(t, "development", delta.Metadata.EnvID)
+	assert.Contains(t, delta.Modules.Add, "web")
+	assert.Equal(t, "${externals.db.host}", delta.Modules.Add["web"]["spec"].(map[string]interface{})["containers"].(map[string]interface{})["main"].(map[string]interface{})["variables"].(map[string]interface{})["DB_HOST"])
+}
+
+func TestRun_output(t *testing.T) {
+	var dir = t.TempDir()
+	var scoreFile = writeFile(t, dir, "score.yaml", testScoreFile)
+
+	stdout, _, err := executeCommand(t, "run", "-f", scoreFile, "--env", "development", "--format", "yaml")
+	assert.NoError(t, err)
+	var delta map[string]interface{}
+	assert.NoError(t, yaml.Unmarshal([]byte(stdout), &delta))
+	assert.Equal(t, map[string]interface{}{"env_id": "development", "name": "Auto-deployment (SCORE)"}, delta["metadata"])
+
+	var outFile = filepath.Join(dir, "delta.json")
+	stdout, _, err = executeCommand(t, "run", "-f", scoreFile, "--env", "development", "--format", "compact", "-o", outFile)
+	assert.NoError(t, err)
+	assert.Empty(t, stdout)
+	raw, err := os.ReadFile(outFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(raw), "\n"))
+	assert.True(t, json.Valid(raw))
+}
+
+func TestRun_errors(t *testing.T) {
+	var dir = t.TempDir()
+	var scoreFile = writeFile(t, dir, "score.yaml", testScoreFile)
+	var unresolvedFile = writeFile(t, dir, "unresolved.yaml", `apiVersion: score.dev/v1b1
+metadata:
+  name: web
+containers:
+  main:
+    image: nginx
+    variables:
+      DB_HOST: ${resources.nil.host}
+`)
+
+	_, _, err := executeCommand(t, "run", "-f", scoreFile)
+	assert.EqualError(t, err, `required flag(s) "env" not set`)
+
+	_, _, err = executeCommand(t, "run", "-f", unresolvedFile, "--env", "development", "--strict")
+	assert.ErrorContains(t, err, "unresolved references")
+	assert.Equal(t, ExitValidation, ExitCode(err))
+}
@@ -0,0 +1,129 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+const (
+	// PathEnvVar is the environment variable that overrides the configuration file location.
+	PathEnvVar = "SCORE_HUMANITEC_CONFIG"
+)
+
+// Context is a named set of defaults for the Humanitec API access.
+type Context struct {
+	Org    string `yaml:"org,omitempty"`
+	App    string `yaml:"app,omitempty"`
+	Env    string `yaml:"env,omitempty"`
+	ApiUrl string `yaml:"api-url,omitempty"`
+	UiUrl  string `yaml:"ui-url,omitempty"`
+	Token  string `yaml:"token,omitempty"`
+
+	RequestTimeout string `yaml:"request-timeout,omitempty"`
+	CAFile         string `yaml:"ca-file,omitempty"`
+	ClientCert     string `yaml:"client-cert,omitempty"`
+	ClientKey      string `yaml:"client-key,omitempty"`
+	Proxy          string `yaml:"proxy,omitempty"`
+}
+
+// Config is the score-humanitec configuration file content.
+//
+// YAML example:
+//
+//	current-context: staging
+//	contexts:
+//	  staging:
+//	    org: my-org
+//	    app: my-app
+//	    env: staging
+//	    api-url: https://api.humanitec.io
+type Config struct {
+	CurrentContext string             `yaml:"current-context,omitempty"`
+	Contexts       map[string]Context `yaml:"contexts,omitempty"`
+}
+
+// DefaultPath returns the configuration file location.
+// Uses the SCORE_HUMANITEC_CONFIG environment variable if set, or '$XDG_CONFIG_HOME/score-humanitec/config.yaml'
+// (defaults to '~/.config/score-humanitec/config.yaml') otherwise.
+func DefaultPath() (string, error) {
+	if path := os.Getenv(PathEnvVar); path != "" {
+		return path, nil
+	}
+	var configDir = os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("locating config file: %w", err)
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configDir, "score-humanitec", "config.yaml"), nil
+}
+
+// Load reads the configuration file. Returns an empty configuration if the file does not exist.
+func Load(path string) (*Config, error) {
+	var cfg Config
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &cfg, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing config file '%s': %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Save writes the configuration file, creating the parent directory if needed.
+// The file is only readable by the current user, since contexts may include API tokens.
+func (c *Config) Save(path string) error {
+	raw, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("marshalling config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0600); err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+	return nil
+}
+
+// Context returns the named context, or the current context if the name is empty.
+// Returns an empty context if no name is given and there is no current context.
+func (c *Config) Context(name string) (*Context, error) {
+	if name == "" {
+		name = c.CurrentContext
+		if name == "" {
+			return &Context{}, nil
+		}
+	}
+	ctx, exists := c.Contexts[name]
+	if !exists {
+		return nil, fmt.Errorf("context '%s' not found", name)
+	}
+	return &ctx, nil
+}
+
+// ContextNames returns the sorted list of all context names.
+func (c *Config) ContextNames() []string {
+	var names = make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,88 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv(PathEnvVar, "")
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	path, err := DefaultPath()
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/xdg/score-humanitec/config.yaml", path)
+
+	t.Setenv(PathEnvVar, "/tmp/custom.yaml")
+	path, err = DefaultPath()
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/custom.yaml", path)
+}
+
+func TestLoadSave(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "score-humanitec", "config.yaml")
+
+	// Missing file
+	cfg, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, &Config{}, cfg)
+
+	// Round trip
+	cfg = &Config{
+		CurrentContext: "staging",
+		Contexts: map[string]Context{
+			"staging":    {Org: "test-org", App: "test-app", Env: "staging", ApiUrl: "https://api.example.com"},
+			"production": {Org: "test-org", App: "test-app", Env: "production", Token: "qwe...rty"},
+		},
+	}
+	assert.NoError(t, cfg.Save(path))
+
+	stat, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), stat.Mode().Perm())
+
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, cfg, loaded)
+
+	// Invalid file
+	assert.NoError(t, os.WriteFile(path, []byte("<NOT A VALID YAML>"), 0600))
+	_, err = Load(path)
+	assert.ErrorContains(t, err, "parsing config file")
+}
+
+func TestContext(t *testing.T) {
+	var cfg = &Config{
+		Contexts: map[string]Context{
+			"staging":    {Org: "test-org", Env: "staging"},
+			"production": {Org: "test-org", Env: "production"},
+		},
+	}
+
+	ctx, err := cfg.Context("")
+	assert.NoError(t, err)
+	assert.Equal(t, &Context{}, ctx)
+
+	cfg.CurrentContext = "staging"
+	ctx, err = cfg.Context("")
+	assert.NoError(t, err)
+	assert.Equal(t, "staging", ctx.Env)
+
+	ctx, err = cfg.Context("production")
+	assert.NoError(t, err)
+	assert.Equal(t, "production", ctx.Env)
+
+	_, err = cfg.Context("nil")
+	assert.EqualError(t, err, "context 'nil' not found")
+
+	assert.Equal(t, []string{"production", "staging"}, cfg.ContextNames())
+}
@@ -5,4 +5,12 @@ const (
 	managedBy                = "score-humanitec"
 	managedByAnnotation      = "humanitec.io/managed-by"
 	workloadSourceAnnotation = "humanitec.io/workload-source"
+
+	// sharedResourcesAnnotation lists the shared resources declared by the workload, as a JSON array, for them to be
+	// pruned once no workload declares them anymore. Only recorded when pruning (see AnnotateSharedResources).
+	sharedResourcesAnnotation = "humanitec.io/shared-resources"
+
+	// ownedPathsAnnotation lists the module fields generated from the SCORE specs by the last update, as a JSON array of
+	// JSON pointers, for them to be removed once the SCORE specs do not generate them anymore.
+	ownedPathsAnnotation = "humanitec.io/owned-paths"
 )
@@ -12,6 +12,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 
 	mergo "github.com/imdario/mergo"
@@ -25,77 +27,155 @@ const (
 	AnnotationLabelResourceId = "score.humanitec.io/resId"
 )
 
+// resourceRef returns the Humanitec resource reference of the workload resource, from the resource annotation,
+// or from the deprecated resources extensions if the annotation is not set.
+func resourceRef(name string, res score.Resource, deprecated extensions.HumanitecResourcesSpecs) string {
+	resAnnotations, _ := res.Metadata["annotations"].(map[string]interface{})
+	if resId, hasAnnotation := resAnnotations[AnnotationLabelResourceId].(string); hasAnnotation {
+		if resId == "" {
+			resId = fmt.Sprintf("externals.%s", name)
+		}
+		return resId
+	}
+	if meta, hasMeta := deprecated[name]; hasMeta && meta.Scope == "shared" {
+		return fmt.Sprintf("shared.%s", name)
+	}
+	return fmt.Sprintf("externals.%s", name)
+}
+
 // parseResourceId extracts resource ID details from a resource reference string.
 // Supported reference string formants:
 //
 //	{resId}
 //	{externals|shared}.{resId}
 //	modules.{workloadId}.{externals|shared}.{resId}
+//
+// The scope is empty for a resource ID only reference, and the workload ID is empty if the module is not specified.
+// A resource ID only reference is an external resource of the workload, i.e. 'db' is the same as 'externals.db'.
 func parseResourceId(ref string) (workload, scope, resId string, err error) {
-	var segments = strings.SplitN(ref, ".", 4)
-	switch len(segments) {
-	case 4:
-		if segments[0] != "modules" {
-			err = fmt.Errorf("invalid resource reference '%s': not supported", ref)
+	var segments = strings.Split(ref, ".")
+	switch {
+	case len(segments) == 4 && segments[0] == "modules":
+		workload, scope, resId = segments[1], segments[2], segments[3]
+		if workload == "" {
+			err = fmt.Errorf("invalid resource reference '%s': missing workload ID", ref)
 			return
 		}
-		workload = segments[1]
-		scope = segments[2]
-		resId = segments[3]
-	case 3:
-		if segments[0] != "modules" {
-			err = fmt.Errorf("invalid resource reference '%s': not supported", ref)
-			return
-		}
-	case 2:
-		workload = ""
-		scope = segments[0]
-		resId = segments[1]
-	case 1:
-		workload = ""
-		scope = ""
+	case len(segments) == 2:
+		scope, resId = segments[0], segments[1]
+	case len(segments) == 1:
 		resId = segments[0]
+		return
 	default:
-		workload = ""
-		scope = ""
-		resId = ""
+		err = fmt.Errorf("invalid resource reference '%s': not supported, expected '{externals|shared}.{resId}' or 'modules.{workloadId}.{externals|shared}.{resId}'", ref)
+		return
+	}
+
+	if scope != "externals" && scope != "shared" {
+		err = fmt.Errorf("invalid resource reference '%s': scope '%s' is not supported, expected 'externals' or 'shared'", ref, scope)
+	} else if resId == "" {
+		err = fmt.Errorf("invalid resource reference '%s': missing resource ID", ref)
 	}
 	return
 }
 
-// getProbeDetails extracts an httpGet probe details from the source spec.
-// Returns nil if the source spec is empty.
-func getProbeDetails(probe *score.ContainerProbe) map[string]interface{} {
-	if probe.HttpGet.Path == "" {
-		return nil
+// getProbeDetails extracts a httpGet, exec or tcpSocket probe details from the source spec.
+// Returns an error if the probe can not be translated, or nil if the probe is skipped (httpGet probe without path).
+func getProbeDetails(probe *ContainerProbe, context *templatesContext) (map[string]interface{}, error) {
+	var handlers = 0
+	var res = map[string]interface{}{}
+
+	if probe.HttpGet != nil {
+		handlers++
+		if probe.HttpGet.Path == "" {
+			context.Warnf("httpGet probe is missing the path and will be ignored.")
+			return nil, nil
+		}
+		if probe.HttpGet.Port == 0 {
+			return nil, fmt.Errorf("httpGet probe is missing the port")
+		}
+		if probe.HttpGet.Host != nil {
+			context.Warnf("httpGet probe host '%s' is not supported and will be ignored.", *probe.HttpGet.Host)
+		}
+		if probe.HttpGet.Scheme != nil && *probe.HttpGet.Scheme != score.HttpProbeSchemeHTTP {
+			context.Warnf("httpGet probe scheme '%s' is not supported and will be ignored.", *probe.HttpGet.Scheme)
+		}
+
+		res["type"] = "http"
+		res["path"] = probe.HttpGet.Path
+		res["port"] = probe.HttpGet.Port
+
+		if len(probe.HttpGet.HttpHeaders) > 0 {
+			var hdrs = map[string]string{}
+			for _, hdr := range probe.HttpGet.HttpHeaders {
+				if hdr.Name != nil && hdr.Value != nil {
+					hdrs[*hdr.Name] = *hdr.Value
+				}
+			}
+			res["headers"] = hdrs
+		}
 	}
+	if probe.Exec != nil {
+		handlers++
+		if len(probe.Exec.Command) == 0 {
+			return nil, fmt.Errorf("exec probe is missing the command")
+		}
+
+		res["type"] = "command"
+		res["command"] = probe.Exec.Command
+	}
+	if probe.TcpSocket != nil {
+		handlers++
+		if probe.TcpSocket.Port == 0 {
+			return nil, fmt.Errorf("tcpSocket probe is missing the port")
+		}
+		if probe.TcpSocket.Host != nil {
+			context.Warnf("tcpSocket probe host '%s' is not supported and will be ignored.", *probe.TcpSocket.Host)
+		}
 
-	var res = map[string]interface{}{
-		"type": "http",
-		"path": probe.HttpGet.Path,
-		"port": probe.HttpGet.Port,
+		res["type"] = "tcp"
+		res["port"] = probe.TcpSocket.Port
 	}
 
-	if len(probe.HttpGet.HttpHeaders) > 0 {
-		var hdrs = map[string]string{}
-		for _, hdr := range probe.HttpGet.HttpHeaders {
-			if hdr.Name != nil && hdr.Value != nil {
-				hdrs[*hdr.Name] = *hdr.Value
-			}
+	switch handlers {
+	case 0:
+		return nil, fmt.Errorf("probe must have one of httpGet, exec or tcpSocket handlers")
+	case 1:
+		break
+	default:
+		return nil, fmt.Errorf("probe must have only one of httpGet, exec or tcpSocket handlers")
+	}
+
+	for key, val := range map[string]*int{
+		"initial_delay_seconds": probe.InitialDelaySeconds,
+		"period_seconds":        probe.PeriodSeconds,
+		"timeout_seconds":       probe.TimeoutSeconds,
+		"success_threshold":     probe.SuccessThreshold,
+		"failure_threshold":     probe.FailureThreshold,
+	} {
+		if val != nil {
+			res[key] = *val
 		}
-		res["headers"] = hdrs
 	}
 
-	return res
+	return res, nil
 }
 
-// readFile reads a text file into memory
-func readFile(baseDir, path string) (string, error) {
+// FileReader reads the content of the file at the path, e.g. a container file source.
+type FileReader func(path string) ([]byte, error)
+
+// readFile reads a text file into memory.
+// Relative paths are resolved against the workload base directory.
+func (w *WorkloadSource) readFile(path string) (string, error) {
 	if !filepath.IsAbs(path) {
-		path = filepath.Join(baseDir, path)
+		path = filepath.Join(w.BaseDir, path)
 	}
 
-	raw, err := os.ReadFile(path)
+	var read = w.ReadFile
+	if read == nil {
+		read = os.ReadFile
+	}
+	raw, err := read(path)
 	if err != nil {
 		return "", fmt.Errorf("reading '%s': %w", path, err)
 	}
@@ -104,13 +184,13 @@ func readFile(baseDir, path string) (string, error) {
 }
 
 // mergeFileContent joins inline file contents with '\n' character (DEPRECATED)
-func mergeFileContent(content interface{}, target string) (string, error) {
+func mergeFileContent(content interface{}, target string, context *templatesContext) (string, error) {
 	switch val := content.(type) {
 	case string:
 		return val, nil
 	case []interface{}:
 		// TODO: Deprecated functionality
-		log.Printf("Warning: The content for the '%s' file is provided in a deprecated format. Strings will be joined with '\\n' character.", target)
+		context.Warnf("The content for the '%s' file is provided in a deprecated format. Strings will be joined with '\\n' character.", target)
 		var sb strings.Builder
 		for _, str := range val {
 			if sb.Len() > 0 {
@@ -126,14 +206,14 @@ func mergeFileContent(content interface{}, target string) (string, error) {
 }
 
 // convertFileMountSpec extracts a mount file details from the source spec.
-func convertFileMountSpec(f *score.ContainerFilesElem, context *templatesContext, baseDir string) (string, map[string]interface{}, error) {
+func convertFileMountSpec(f *score.ContainerFilesElem, context *templatesContext, readFile func(path string) (string, error)) (string, map[string]interface{}, error) {
 	var err error
 	var content string
 
 	if f.Source != nil {
-		content, err = readFile(baseDir, *f.Source)
+		content, err = readFile(*f.Source)
 	} else if f.Content != nil {
-		content, err = mergeFileContent(*f.Content, f.Target)
+		content, err = mergeFileContent(*f.Content, f.Target, context)
 	} else {
 		err = fmt.Errorf("file is missing source or content")
 	}
@@ -144,7 +224,7 @@ func convertFileMountSpec(f *score.ContainerFilesElem, context *templatesContext
 	if f.NoExpand != nil && *f.NoExpand {
 		content = context.Escape(content)
 	} else {
-		content = context.Substitute(content)
+		content = context.At(joinLocation(context.location, f.Target)).Substitute(content)
 	}
 
 	return f.Target,
@@ -156,7 +236,8 @@ func convertFileMountSpec(f *score.ContainerFilesElem, context *templatesContext
 }
 
 // convertContainerSpec extracts a container details from the source spec.
-func convertContainerSpec(name string, spec *score.Container, context *templatesContext, baseDir string) (map[string]interface{}, error) {
+// The extended probes, if provided, take precedence over the SCORE ones.
+func convertContainerSpec(name string, spec *score.Container, probes ContainerProbes, context *templatesContext, readFile func(path string) (string, error)) (map[string]interface{}, error) {
 	var containerSpec = map[string]interface{}{
 		"id": name,
 	}
@@ -172,7 +253,7 @@ func convertContainerSpec(name string, spec *score.Container, context *templates
 	if len(spec.Variables) > 0 {
 		var envVars = make(map[string]interface{}, len(spec.Variables))
 		for key, val := range spec.Variables {
-			envVars[key] = context.Substitute(val)
+			envVars[key] = context.At(fmt.Sprintf("containers.%s.variables.%s", name, key)).Substitute(val)
 		}
 		containerSpec["variables"] = envVars
 	}
@@ -188,20 +269,34 @@ func convertContainerSpec(name string, spec *score.Container, context *templates
 			containerSpec["resources"] = containerResources
 		}
 	}
-	if spec.LivenessProbe != nil {
-		if probe := getProbeDetails(spec.LivenessProbe); len(probe) > 0 {
+	if probes.Liveness == nil {
+		probes.Liveness = fromScoreProbe(spec.LivenessProbe)
+	}
+	if probes.Liveness != nil {
+		probe, err := getProbeDetails(probes.Liveness, context)
+		if err != nil {
+			return nil, fmt.Errorf("liveness probe: %w", err)
+		}
+		if probe != nil {
 			containerSpec["liveness_probe"] = probe
 		}
 	}
-	if spec.ReadinessProbe != nil {
-		if probe := getProbeDetails(spec.ReadinessProbe); len(probe) > 0 {
+	if probes.Readiness == nil {
+		probes.Readiness = fromScoreProbe(spec.ReadinessProbe)
+	}
+	if probes.Readiness != nil {
+		probe, err := getProbeDetails(probes.Readiness, context)
+		if err != nil {
+			return nil, fmt.Errorf("readiness probe: %w", err)
+		}
+		if probe != nil {
 			containerSpec["readiness_probe"] = probe
 		}
 	}
 	if len(spec.Files) > 0 {
 		var files = map[string]interface{}{}
 		for _, f := range spec.Files {
-			if target, mount, err := convertFileMountSpec(&f, context, baseDir); err == nil {
+			if target, mount, err := convertFileMountSpec(&f, context.At(fmt.Sprintf("containers.%s.files", name)), readFile); err == nil {
 				files[target] = mount
 			} else {
 				return nil, err
@@ -213,7 +308,7 @@ func convertContainerSpec(name string, spec *score.Container, context *templates
 		var volumes = map[string]interface{}{}
 		for _, vol := range spec.Volumes {
 			volumes[vol.Target] = map[string]interface{}{
-				"id":        context.Substitute(vol.Source),
+				"id":        context.At(fmt.Sprintf("containers.%s.volumes.%s", name, vol.Target)).Substitute(vol.Source),
 				"sub_path":  DerefOr(vol.Path, ""),
 				"read_only": DerefOr(vol.ReadOnly, false),
 			}
@@ -226,6 +321,9 @@ func convertContainerSpec(name string, spec *score.Container, context *templates
 
 func getContainerResources(requests *score.ResourcesLimits) map[string]interface{} {
 	out := make(map[string]interface{})
+	if requests == nil {
+		return out
+	}
 	if requests.Cpu != nil {
 		out["cpu"] = *requests.Cpu
 	}
@@ -237,9 +335,22 @@ func getContainerResources(requests *score.ResourcesLimits) map[string]interface
 
 // ConvertSpec converts SCORE specification into Humanitec deployment delta.
 func ConvertSpec(name, envID, baseDir, workloadSourceURL string, spec *score.Workload, ext *extensions.HumanitecExtensionsSpec) (*humanitec.CreateDeploymentDeltaRequest, error) {
+	res, _, _, err := convertSpec(name, envID, workloadSourceURL, WorkloadSource{BaseDir: baseDir, Spec: spec, Extensions: ext}, nil)
+	return res, err
+}
+
+// convertSpec converts SCORE specification into Humanitec deployment delta.
+// The workloads converted together, if any, are used to resolve the 'workload' resources outputs.
+// Returns the list of '${...}' references that could not be resolved, and the conversion warnings.
+func convertSpec(name, envID, workloadSourceURL string, source WorkloadSource, workloads map[string]*score.Workload) (*humanitec.CreateDeploymentDeltaRequest, []UnresolvedReference, []Warning, error) {
+	var spec, ext = source.Spec, source.Extensions
 	ctx, err := buildContext(spec.Metadata, spec.Resources, ext.Resources)
 	if err != nil {
-		return nil, fmt.Errorf("preparing context: %w", err)
+		return nil, nil, nil, fmt.Errorf("preparing context: %w", err)
+	}
+	ctx.workloads = workloads
+	if source.Logger != nil {
+		ctx.logger = source.Logger
 	}
 	annotations := map[string]interface{}{
 		managedByAnnotation: managedBy,
@@ -250,10 +361,10 @@ func ConvertSpec(name, envID, baseDir, workloadSourceURL string, spec *score.Wor
 
 	var containers = make(map[string]interface{}, len(spec.Containers))
 	for cName, cSpec := range spec.Containers {
-		if container, err := convertContainerSpec(cName, &cSpec, ctx, baseDir); err == nil {
+		if container, err := convertContainerSpec(cName, &cSpec, source.Probes[cName], ctx, source.readFile); err == nil {
 			containers[cName] = container
 		} else {
-			return nil, fmt.Errorf("processing container specification for '%s': %w", cName, err)
+			return nil, nil, nil, fmt.Errorf("processing container specification for '%s': %w", cName, err)
 		}
 	}
 
@@ -276,9 +387,9 @@ func ConvertSpec(name, envID, baseDir, workloadSourceURL string, spec *score.Wor
 	}
 
 	if ext != nil && len(ext.Spec) > 0 {
-		var features = ctx.SubstituteAll(ext.Spec)
+		var features = ctx.At("extensions.spec").SubstituteAll(ext.Spec)
 		if err := mergo.Merge(&workloadSpec, features); err != nil {
-			return nil, fmt.Errorf("applying workload profile features: %w", err)
+			return nil, nil, nil, fmt.Errorf("applying workload profile features: %w", err)
 		}
 	}
 
@@ -297,60 +408,51 @@ func ConvertSpec(name, envID, baseDir, workloadSourceURL string, spec *score.Wor
 	for name, res := range spec.Resources {
 		switch res.Type {
 
-		case "service", "environment":
+		case "service", "environment", "workload":
 			continue
 
 		default:
-			resAnnotations, _ := res.Metadata["annotations"].(map[string]interface{})
-			resId, hasAnnotation := resAnnotations[AnnotationLabelResourceId].(string)
-			if resId == "" {
-				resId = fmt.Sprintf("externals.%s", name)
-			}
-
 			// DEPRECATED: Should use resource annotations instead
-			if meta, hasMeta := ext.Resources[name]; hasMeta {
-				log.Printf("Warning: Extensions for resources has been deprecated. Use '%s' resource annotation instead. Extensions are still configured for '%s'.\n", AnnotationLabelResourceId, name)
-				if !hasAnnotation && (meta.Scope == "" || meta.Scope == "externals") {
-					resId = fmt.Sprintf("externals.%s", name)
-				} else if !hasAnnotation && meta.Scope == "shared" {
-					resId = fmt.Sprintf("shared.%s", name)
-				}
+			if _, hasMeta := ext.Resources[name]; hasMeta {
+				ctx.Warnf("Extensions for resources has been deprecated. Use '%s' resource annotation instead. Extensions are still configured for '%s'.", AnnotationLabelResourceId, name)
 			}
 			// END (DEPRECATED)
 			var class = DerefOr(res.Class, "default")
-			if mod, scope, resName, err := parseResourceId(resId); err != nil {
-				log.Printf("Warning: %v.\n", err)
-			} else if mod == "" || mod == spec.Metadata["name"].(string) {
-				if scope == "externals" {
-					var extRes = map[string]interface{}{
-						"type":  res.Type,
-						"class": class,
-					}
-					if len(res.Params) > 0 {
-						extRes["params"] = ctx.SubstituteAll(res.Params)
-					}
-					externals[resName] = extRes
-				} else if scope == "shared" {
-					var resName = strings.Replace(resId, "shared.", "", 1)
-					var sharedRes = map[string]interface{}{
-						"type":  res.Type,
-						"class": class,
-					}
-					if len(res.Params) > 0 {
-						sharedRes["params"] = ctx.SubstituteAll(res.Params)
-					}
-					if class != "default" {
-						sharedRes["id"] = resId
-						resName = resName + "-class-" + class
-					}
-					shared = append(shared, humanitec.UpdateAction{
-						Operation: "add",
-						Path:      "/" + resName,
-						Value:     sharedRes,
-					})
-				} else {
-					log.Printf("Warning: invalid resource reference '%s': not supported.\n", resId)
+			mod, scope, resName, err := parseResourceId(resourceRef(name, res, ext.Resources))
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("resource '%s': %w", name, err)
+			}
+			if mod != "" && mod != spec.Metadata["name"].(string) {
+				// Resources of other workloads are provisioned by their own modules, and only referenced here
+				continue
+			}
+			switch scope {
+			case "", "externals":
+				var extRes = map[string]interface{}{
+					"type":  res.Type,
+					"class": class,
+				}
+				if len(res.Params) > 0 {
+					extRes["params"] = ctx.At(fmt.Sprintf("resources.%s.params", name)).SubstituteAll(res.Params)
+				}
+				externals[resName] = extRes
+			case "shared":
+				var sharedRes = map[string]interface{}{
+					"type":  res.Type,
+					"class": class,
 				}
+				if len(res.Params) > 0 {
+					sharedRes["params"] = ctx.At(fmt.Sprintf("resources.%s.params", name)).SubstituteAll(res.Params)
+				}
+				if class != "default" {
+					sharedRes["id"] = "shared." + resName
+					resName = resName + "-class-" + class
+				}
+				shared = append(shared, humanitec.UpdateAction{
+					Operation: "add",
+					Path:      "/" + resName,
+					Value:     sharedRes,
+				})
 			}
 		}
 	}
@@ -373,5 +475,169 @@ func ConvertSpec(name, envID, baseDir, workloadSourceURL string, spec *score.Wor
 		res.Shared = shared
 	}
 
+	return &res, ctx.Unresolved(), ctx.Warnings(), nil
+}
+
+// WorkloadSource is a single workload specification to be converted with ConvertSpecs.
+type WorkloadSource struct {
+	BaseDir    string
+	Spec       *score.Workload
+	Extensions *extensions.HumanitecExtensionsSpec
+
+	// Probes are the containers probes that can not be expressed with SCORE types (see ExtractProbes).
+	Probes map[string]ContainerProbes
+
+	// ReadFile reads the containers files sources. Defaults to os.ReadFile if not set.
+	ReadFile FileReader
+
+	// Logger receives the conversion diagnostic messages. They are discarded if not set.
+	Logger *log.Logger
+}
+
+// ConvertSpecs converts several SCORE specifications into a single Humanitec deployment delta.
+// Shared resources declared by more than one workload are added only once.
+// In strict mode, any '${...}' reference that can not be resolved is reported as an UnresolvedReferencesError,
+// otherwise it is returned as a warning, along with the other conversion warnings.
+func ConvertSpecs(name, envID, workloadSourceURL string, workloads []WorkloadSource, strict bool) (*humanitec.CreateDeploymentDeltaRequest, []Warning, error) {
+	var known = make(map[string]*score.Workload, len(workloads))
+	for _, w := range workloads {
+		var wName, _ = w.Spec.Metadata["name"].(string)
+		if _, exists := known[wName]; exists {
+			return nil, nil, fmt.Errorf("duplicate workload name '%s'", wName)
+		}
+		known[wName] = w.Spec
+	}
+
+	var deltas = make([]*humanitec.CreateDeploymentDeltaRequest, 0, len(workloads))
+	var unresolved []UnresolvedReference
+	var warnings = []Warning{}
+	for _, w := range workloads {
+		var wName, _ = w.Spec.Metadata["name"].(string)
+		if len(workloads) > 1 {
+			var resNames = make([]string, 0, len(w.Spec.Resources))
+			for resName := range w.Spec.Resources {
+				resNames = append(resNames, resName)
+			}
+			sort.Strings(resNames)
+			for _, resName := range resNames {
+				if _, exists := known[resName]; exists {
+					continue
+				}
+				switch w.Spec.Resources[resName].Type {
+				case "service":
+					warnings = append(warnings, Warning{Workload: wName, Message: fmt.Sprintf("service '%s' is not one of the converted workloads.", resName)})
+				case "workload":
+					return nil, nil, fmt.Errorf("workload '%s' depends on workload '%s', which is not one of the converted workloads", wName, resName)
+				}
+			}
+		}
+
+		delta, refs, warns, err := convertSpec(name, envID, workloadSourceURL, w, known)
+		if err != nil {
+			return nil, nil, fmt.Errorf("converting workload '%s': %w", wName, err)
+		}
+		deltas = append(deltas, delta)
+		for _, ref := range refs {
+			ref.Workload = wName
+			unresolved = append(unresolved, ref)
+		}
+		for _, warn := range warns {
+			warn.Workload = wName
+			warnings = append(warnings, warn)
+		}
+	}
+
+	if strict && len(unresolved) > 0 {
+		return nil, nil, &UnresolvedReferencesError{References: unresolved}
+	}
+	for _, ref := range unresolved {
+		warnings = append(warnings, Warning{Workload: ref.Workload, Message: fmt.Sprintf("Can not resolve '${%s}' in %s.", ref.Ref, ref.Location)})
+	}
+
+	res, err := MergeDeltas(name, envID, deltas...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(workloads) > 1 {
+		if err := checkModuleReferences(workloads, res); err != nil {
+			return nil, nil, err
+		}
+	}
+	return res, warnings, nil
+}
+
+// checkModuleReferences checks that the 'modules.{workloadId}.externals.{resId}' resources references are declared,
+// with the same type, by the converted workloads. Like workload dependencies, the referenced workloads must be converted
+// together.
+func checkModuleReferences(workloads []WorkloadSource, delta *humanitec.CreateDeploymentDeltaRequest) error {
+	for _, w := range workloads {
+		var wName, _ = w.Spec.Metadata["name"].(string)
+
+		var resNames = make([]string, 0, len(w.Spec.Resources))
+		for resName := range w.Spec.Resources {
+			resNames = append(resNames, resName)
+		}
+		sort.Strings(resNames)
+
+		for _, resName := range resNames {
+			var res = w.Spec.Resources[resName]
+			var resAnnotations, _ = res.Metadata["annotations"].(map[string]interface{})
+			var resId, _ = resAnnotations[AnnotationLabelResourceId].(string)
+			mod, scope, id, err := parseResourceId(resId)
+			if err != nil || mod == "" || mod == wName || scope != "externals" {
+				continue
+			}
+
+			module, known := delta.Modules.Add[mod]
+			if !known {
+				return fmt.Errorf("resource '%s' used by workload '%s' refers to '%s', but workload '%s' is not one of the converted workloads", resName, wName, resId, mod)
+			}
+			var externals, _ = module["externals"].(map[string]interface{})
+			external, declared := externals[id].(map[string]interface{})
+			if !declared {
+				return fmt.Errorf("resource '%s' used by workload '%s' refers to '%s', which is not declared by workload '%s'", resName, wName, resId, mod)
+			}
+			if external["type"] != res.Type {
+				return fmt.Errorf("resource '%s' used by workload '%s' has type '%s', but '%s' has type '%v'", resName, wName, res.Type, resId, external["type"])
+			}
+		}
+	}
+	return nil
+}
+
+// MergeDeltas combines several deployment deltas into a single one.
+// Identical shared resource actions are de-duplicated, while conflicting ones are reported as errors.
+func MergeDeltas(name, envID string, deltas ...*humanitec.CreateDeploymentDeltaRequest) (*humanitec.CreateDeploymentDeltaRequest, error) {
+	var res = humanitec.CreateDeploymentDeltaRequest{
+		Metadata: humanitec.DeltaMetadata{
+			Name:  name,
+			EnvID: envID,
+		},
+		Modules: humanitec.ModuleDeltas{
+			Add: map[string]map[string]interface{}{},
+		},
+	}
+
+	var shared = make(map[string]humanitec.UpdateAction)
+	for _, delta := range deltas {
+		for modName, mod := range delta.Modules.Add {
+			if _, exists := res.Modules.Add[modName]; exists {
+				return nil, fmt.Errorf("duplicate module '%s'", modName)
+			}
+			res.Modules.Add[modName] = mod
+		}
+		for _, action := range delta.Shared {
+			var key = action.Operation + " " + action.Path
+			if prev, exists := shared[key]; exists {
+				if !reflect.DeepEqual(prev, action) {
+					return nil, fmt.Errorf("conflicting definitions for shared resource '%s'", strings.TrimPrefix(action.Path, "/"))
+				}
+				continue
+			}
+			shared[key] = action
+			res.Shared = append(res.Shared, action)
+		}
+	}
+
 	return &res, nil
 }
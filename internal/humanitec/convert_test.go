@@ -76,6 +76,26 @@ func TestParseResourceId(t *testing.T) {
 			ResourceReference: "something.test-something.externals.test-res-id",
 			ExpectedError:     errors.New("not supported"),
 		},
+		{
+			Name:              "Should reject module reference without resource ID",
+			ResourceReference: "modules.test-module.externals",
+			ExpectedError:     errors.New("invalid resource reference 'modules.test-module.externals': not supported"),
+		},
+		{
+			Name:              "Should reject module reference without workload ID",
+			ResourceReference: "modules..externals.test-res-id",
+			ExpectedError:     errors.New("missing workload ID"),
+		},
+		{
+			Name:              "Should reject unknown scopes",
+			ResourceReference: "modules.test-module.service.test-res-id",
+			ExpectedError:     errors.New("scope 'service' is not supported"),
+		},
+		{
+			Name:              "Should reject empty resource ID",
+			ResourceReference: "externals.",
+			ExpectedError:     errors.New("missing resource ID"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -495,6 +515,105 @@ DEBUG: ${resources.env.DEBUG}
 				},
 			},
 		},
+		{
+			Name: "Should convert references inside lists",
+			Source: &score.Workload{
+				Metadata: score.WorkloadMetadata{
+					"name": "test",
+				},
+				Containers: score.WorkloadContainers{
+					"backend": score.Container{
+						Image: "busybox",
+					},
+				},
+				Resources: map[string]score.Resource{
+					"dns": {
+						Metadata: score.ResourceMetadata{
+							"annotations": map[string]interface{}{
+								AnnotationLabelResourceId: "shared.dns",
+							},
+						},
+						Type: "dns",
+					},
+					"route": {
+						Type: "route",
+						Params: map[string]interface{}{
+							"hosts": []interface{}{"${resources.dns.host}", "$${resources.dns.host}"},
+							"rules": []interface{}{
+								map[string]interface{}{
+									"paths": []interface{}{"/${metadata.name}"},
+								},
+							},
+						},
+					},
+				},
+			},
+			Extensions: &extensions.HumanitecExtensionsSpec{
+				Spec: map[string]interface{}{
+					"ingress": map[string]interface{}{
+						"tls": []interface{}{
+							map[string]interface{}{
+								"hosts":      []interface{}{"${resources.dns.host}"},
+								"secretName": "${metadata.name}-tls",
+							},
+						},
+					},
+				},
+			},
+			Output: &humanitec.CreateDeploymentDeltaRequest{
+				Metadata: humanitec.DeltaMetadata{EnvID: envID, Name: name},
+				Modules: humanitec.ModuleDeltas{
+					Add: map[string]map[string]interface{}{
+						"test": {
+							"profile": "humanitec/default-module",
+							"spec": map[string]interface{}{
+								"annotations": map[string]interface{}{
+									"humanitec.io/managed-by": "score-humanitec",
+								},
+								"containers": map[string]interface{}{
+									"backend": map[string]interface{}{
+										"id":    "backend",
+										"image": "busybox",
+									},
+								},
+								"ingress": map[string]interface{}{
+									"tls": []interface{}{
+										map[string]interface{}{
+											"hosts":      []interface{}{"${shared.dns.host}"},
+											"secretName": "test-tls",
+										},
+									},
+								},
+							},
+							"externals": map[string]interface{}{
+								"route": map[string]interface{}{
+									"type":  "route",
+									"class": "default",
+									"params": map[string]interface{}{
+										"hosts": []interface{}{"${shared.dns.host}", "${resources.dns.host}"},
+										"rules": []interface{}{
+											map[string]interface{}{
+												"paths": []interface{}{"/test"},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				Shared: []humanitec.UpdateAction{
+					{
+						Operation: "add",
+						Path:      "/dns",
+						Value: map[string]interface{}{
+							"type":  "dns",
+							"class": "default",
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -519,3 +638,734 @@ DEBUG: ${resources.env.DEBUG}
 		})
 	}
 }
+
+func TestScoreConvertMultiple(t *testing.T) {
+	const (
+		envID = "test"
+		name  = "Test delta"
+	)
+
+	var frontend = &score.Workload{
+		Metadata: score.WorkloadMetadata{
+			"name": "frontend",
+		},
+		Containers: score.WorkloadContainers{
+			"frontend": score.Container{
+				Image: "nginx",
+				Variables: map[string]string{
+					"BACKEND_URL": "http://${resources.backend.name}:${resources.backend.port}",
+					"DOMAIN_NAME": "${resources.dns.host}",
+				},
+			},
+		},
+		Resources: map[string]score.Resource{
+			"backend": {Type: "service"},
+			"dns": {
+				Metadata: score.ResourceMetadata{
+					"annotations": map[string]interface{}{
+						AnnotationLabelResourceId: "shared.dns",
+					},
+				},
+				Type: "dns",
+			},
+		},
+	}
+	var backend = &score.Workload{
+		Metadata: score.WorkloadMetadata{
+			"name": "backend",
+		},
+		Service: &score.WorkloadService{
+			Ports: score.WorkloadServicePorts{
+				"www": score.ServicePort{Port: 80},
+			},
+		},
+		Containers: score.WorkloadContainers{
+			"backend": score.Container{
+				Image: "busybox",
+				Variables: map[string]string{
+					"DOMAIN_NAME": "${resources.dns.host}",
+				},
+			},
+		},
+		Resources: map[string]score.Resource{
+			"dns": {
+				Metadata: score.ResourceMetadata{
+					"annotations": map[string]interface{}{
+						AnnotationLabelResourceId: "shared.dns",
+					},
+				},
+				Type: "dns",
+			},
+		},
+	}
+
+	var database = &score.Workload{
+		Metadata: score.WorkloadMetadata{
+			"name": "database",
+		},
+		Containers: score.WorkloadContainers{
+			"main": score.Container{
+				Image: "busybox",
+				Variables: map[string]string{
+					"DB_HOST": "${resources.db.host}",
+				},
+			},
+		},
+		Resources: map[string]score.Resource{
+			"db": {Type: "postgres"},
+		},
+	}
+	var api = &score.Workload{
+		Metadata: score.WorkloadMetadata{
+			"name": "api",
+		},
+		Containers: score.WorkloadContainers{
+			"main": score.Container{
+				Image: "busybox",
+				Variables: map[string]string{
+					"DB_HOST": "${resources.postgres.host}",
+				},
+			},
+		},
+		Resources: map[string]score.Resource{
+			"postgres": {
+				Metadata: score.ResourceMetadata{
+					"annotations": map[string]interface{}{
+						AnnotationLabelResourceId: "modules.database.externals.db",
+					},
+				},
+				Type: "postgres",
+			},
+		},
+	}
+
+	var tests = []struct {
+		Name      string
+		Workloads []WorkloadSource
+		Strict    bool
+		Output    *humanitec.CreateDeploymentDeltaRequest
+		Warnings  []Warning
+		Error     error
+	}{
+		{
+			Name: "Should combine workloads into a single deployment delta",
+			Workloads: []WorkloadSource{
+				{Spec: frontend, Extensions: &extensions.HumanitecExtensionsSpec{}},
+				{Spec: backend, Extensions: &extensions.HumanitecExtensionsSpec{}},
+			},
+			Output: &humanitec.CreateDeploymentDeltaRequest{
+				Metadata: humanitec.DeltaMetadata{EnvID: envID, Name: name},
+				Modules: humanitec.ModuleDeltas{
+					Add: map[string]map[string]interface{}{
+						"frontend": {
+							"profile": "humanitec/default-module",
+							"spec": map[string]interface{}{
+								"annotations": map[string]interface{}{
+									"humanitec.io/managed-by": "score-humanitec",
+								},
+								"containers": map[string]interface{}{
+									"frontend": map[string]interface{}{
+										"id":    "frontend",
+										"image": "nginx",
+										"variables": map[string]interface{}{
+											"BACKEND_URL": "http://${modules.backend.service.name}:${modules.backend.service.port}",
+											"DOMAIN_NAME": "${shared.dns.host}",
+										},
+									},
+								},
+							},
+						},
+						"backend": {
+							"profile": "humanitec/default-module",
+							"spec": map[string]interface{}{
+								"annotations": map[string]interface{}{
+									"humanitec.io/managed-by": "score-humanitec",
+								},
+								"containers": map[string]interface{}{
+									"backend": map[string]interface{}{
+										"id":    "backend",
+										"image": "busybox",
+										"variables": map[string]interface{}{
+											"DOMAIN_NAME": "${shared.dns.host}",
+										},
+									},
+								},
+								"service": map[string]interface{}{
+									"ports": map[string]interface{}{
+										"www": map[string]interface{}{
+											"protocol":       "TCP",
+											"service_port":   80,
+											"container_port": 80,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				Shared: []humanitec.UpdateAction{
+					{
+						Operation: "add",
+						Path:      "/dns",
+						Value: map[string]interface{}{
+							"type":  "dns",
+							"class": "default",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "Should reference the external resources of other workloads",
+			Workloads: []WorkloadSource{
+				{Spec: database, Extensions: &extensions.HumanitecExtensionsSpec{}},
+				{Spec: api, Extensions: &extensions.HumanitecExtensionsSpec{}},
+			},
+			Output: &humanitec.CreateDeploymentDeltaRequest{
+				Metadata: humanitec.DeltaMetadata{EnvID: envID, Name: name},
+				Modules: humanitec.ModuleDeltas{
+					Add: map[string]map[string]interface{}{
+						"database": {
+							"profile": "humanitec/default-module",
+							"spec": map[string]interface{}{
+								"annotations": map[string]interface{}{
+									"humanitec.io/managed-by": "score-humanitec",
+								},
+								"containers": map[string]interface{}{
+									"main": map[string]interface{}{
+										"id":    "main",
+										"image": "busybox",
+										"variables": map[string]interface{}{
+											"DB_HOST": "${externals.db.host}",
+										},
+									},
+								},
+							},
+							"externals": map[string]interface{}{
+								"db": map[string]interface{}{
+									"type":  "postgres",
+									"class": "default",
+								},
+							},
+						},
+						"api": {
+							"profile": "humanitec/default-module",
+							"spec": map[string]interface{}{
+								"annotations": map[string]interface{}{
+									"humanitec.io/managed-by": "score-humanitec",
+								},
+								"containers": map[string]interface{}{
+									"main": map[string]interface{}{
+										"id":    "main",
+										"image": "busybox",
+										"variables": map[string]interface{}{
+											"DB_HOST": "${modules.database.externals.db.host}",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "Should resolve the outputs of workload dependencies",
+			Workloads: []WorkloadSource{
+				{Spec: backend, Extensions: &extensions.HumanitecExtensionsSpec{}},
+				{
+					Spec: &score.Workload{
+						Metadata: score.WorkloadMetadata{"name": "gateway"},
+						Containers: score.WorkloadContainers{
+							"main": score.Container{
+								Image: "nginx",
+								Variables: map[string]string{
+									"UPSTREAM": "${resources.backend.name}=http://${resources.backend.host}:${resources.backend.ports.www}",
+								},
+							},
+						},
+						Resources: map[string]score.Resource{"backend": {Type: "workload"}},
+					},
+					Extensions: &extensions.HumanitecExtensionsSpec{},
+				},
+			},
+			Strict: true,
+			Output: &humanitec.CreateDeploymentDeltaRequest{
+				Metadata: humanitec.DeltaMetadata{EnvID: envID, Name: name},
+				Modules: humanitec.ModuleDeltas{
+					Add: map[string]map[string]interface{}{
+						"backend": {
+							"profile": "humanitec/default-module",
+							"spec": map[string]interface{}{
+								"annotations": map[string]interface{}{
+									"humanitec.io/managed-by": "score-humanitec",
+								},
+								"containers": map[string]interface{}{
+									"backend": map[string]interface{}{
+										"id":    "backend",
+										"image": "busybox",
+										"variables": map[string]interface{}{
+											"DOMAIN_NAME": "${shared.dns.host}",
+										},
+									},
+								},
+								"service": map[string]interface{}{
+									"ports": map[string]interface{}{
+										"www": map[string]interface{}{
+											"protocol":       "TCP",
+											"service_port":   80,
+											"container_port": 80,
+										},
+									},
+								},
+							},
+						},
+						"gateway": {
+							"profile": "humanitec/default-module",
+							"spec": map[string]interface{}{
+								"annotations": map[string]interface{}{
+									"humanitec.io/managed-by": "score-humanitec",
+								},
+								"containers": map[string]interface{}{
+									"main": map[string]interface{}{
+										"id":    "main",
+										"image": "nginx",
+										"variables": map[string]interface{}{
+											"UPSTREAM": "backend=http://${modules.backend.service.name}:80",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				Shared: []humanitec.UpdateAction{
+					{
+						Operation: "add",
+						Path:      "/dns",
+						Value: map[string]interface{}{
+							"type":  "dns",
+							"class": "default",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "Should reject dependencies on unknown workloads",
+			Workloads: []WorkloadSource{
+				{Spec: backend, Extensions: &extensions.HumanitecExtensionsSpec{}},
+				{
+					Spec: &score.Workload{
+						Metadata:  score.WorkloadMetadata{"name": "gateway"},
+						Resources: map[string]score.Resource{"frontend": {Type: "workload"}},
+					},
+					Extensions: &extensions.HumanitecExtensionsSpec{},
+				},
+			},
+			Error: errors.New("workload 'gateway' depends on workload 'frontend', which is not one of the converted workloads"),
+		},
+		{
+			Name: "Should reject references to undeclared resources of other workloads",
+			Workloads: []WorkloadSource{
+				{Spec: backend, Extensions: &extensions.HumanitecExtensionsSpec{}},
+				{Spec: api, Extensions: &extensions.HumanitecExtensionsSpec{}},
+				{Spec: &score.Workload{Metadata: score.WorkloadMetadata{"name": "database"}}, Extensions: &extensions.HumanitecExtensionsSpec{}},
+			},
+			Error: errors.New("resource 'postgres' used by workload 'api' refers to 'modules.database.externals.db', which is not declared by workload 'database'"),
+		},
+		{
+			Name: "Should reject references to resources of workloads not converted together",
+			Workloads: []WorkloadSource{
+				{Spec: backend, Extensions: &extensions.HumanitecExtensionsSpec{}},
+				{Spec: api, Extensions: &extensions.HumanitecExtensionsSpec{}},
+			},
+			Error: errors.New("resource 'postgres' used by workload 'api' refers to 'modules.database.externals.db', but workload 'database' is not one of the converted workloads"),
+		},
+		{
+			Name: "Should reject references to resources of other workloads with a different type",
+			Workloads: []WorkloadSource{
+				{
+					Spec: &score.Workload{
+						Metadata:   score.WorkloadMetadata{"name": "database"},
+						Containers: score.WorkloadContainers{"main": score.Container{Image: "busybox"}},
+						Resources:  map[string]score.Resource{"db": {Type: "mysql"}},
+					},
+					Extensions: &extensions.HumanitecExtensionsSpec{},
+				},
+				{Spec: api, Extensions: &extensions.HumanitecExtensionsSpec{}},
+			},
+			Error: errors.New("resource 'postgres' used by workload 'api' has type 'postgres', but 'modules.database.externals.db' has type 'mysql'"),
+		},
+		{
+			Name: "Should reject malformed resource references",
+			Workloads: []WorkloadSource{
+				{
+					Spec: &score.Workload{
+						Metadata: score.WorkloadMetadata{"name": "api"},
+						Resources: map[string]score.Resource{
+							"db": {
+								Metadata: score.ResourceMetadata{
+									"annotations": map[string]interface{}{
+										AnnotationLabelResourceId: "modules.database.db",
+									},
+								},
+								Type: "postgres",
+							},
+						},
+					},
+					Extensions: &extensions.HumanitecExtensionsSpec{},
+				},
+			},
+			Error: errors.New("converting workload 'api': resource 'db': invalid resource reference 'modules.database.db': not supported"),
+		},
+		{
+			Name: "Should reject duplicate workload names",
+			Workloads: []WorkloadSource{
+				{Spec: backend, Extensions: &extensions.HumanitecExtensionsSpec{}},
+				{Spec: backend, Extensions: &extensions.HumanitecExtensionsSpec{}},
+			},
+			Error: errors.New("duplicate workload name 'backend'"),
+		},
+		{
+			Name: "Should report all unresolved references in strict mode",
+			Workloads: []WorkloadSource{
+				{
+					Spec: &score.Workload{
+						Metadata: score.WorkloadMetadata{
+							"name": "test",
+						},
+						Containers: score.WorkloadContainers{
+							"backend": score.Container{
+								Variables: map[string]string{
+									"DB_HOST":    "${resources.dbb.host}",
+									"LOGS_LEVEL": "${pod.debug.level}",
+								},
+								Files: []score.ContainerFilesElem{
+									{Target: "/etc/config", Content: Ref("${metadata.nmae}")},
+								},
+							},
+						},
+						Resources: map[string]score.Resource{
+							"route": {
+								Type: "route",
+								Params: map[string]interface{}{
+									"host": "${resources.dns.host}",
+								},
+							},
+						},
+					},
+					Extensions: &extensions.HumanitecExtensionsSpec{
+						Spec: map[string]interface{}{
+							"labels": map[string]interface{}{
+								"env": "${resources.env.ENV}",
+							},
+						},
+					},
+				},
+			},
+			Strict: true,
+			Error: errors.New(`unresolved references:
+  - '${metadata.nmae}' in containers.backend.files./etc/config (workload 'test')
+  - '${resources.dbb.host}' in containers.backend.variables.DB_HOST (workload 'test')
+  - '${resources.env.ENV}' in extensions.spec.labels.env (workload 'test')
+  - '${resources.dns.host}' in resources.route.params.host (workload 'test')`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			res, warnings, err := ConvertSpecs(name, envID, "", tt.Workloads, tt.Strict)
+
+			if tt.Error != nil {
+				// On Error
+				//
+				assert.ErrorContains(t, err, tt.Error.Error())
+			} else {
+				// On Success
+				//
+				assert.NoError(t, err)
+				assert.Equal(t, tt.Output, res)
+				if tt.Warnings == nil {
+					tt.Warnings = []Warning{}
+				}
+				assert.Equal(t, tt.Warnings, warnings)
+			}
+		})
+	}
+}
+
+func TestMergeDeltas(t *testing.T) {
+	var dnsAction = humanitec.UpdateAction{
+		Operation: "add",
+		Path:      "/dns",
+		Value:     map[string]interface{}{"type": "dns", "class": "default"},
+	}
+
+	res, err := MergeDeltas("Test delta", "test",
+		&humanitec.CreateDeploymentDeltaRequest{Shared: []humanitec.UpdateAction{dnsAction}},
+		&humanitec.CreateDeploymentDeltaRequest{Shared: []humanitec.UpdateAction{dnsAction}},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []humanitec.UpdateAction{dnsAction}, res.Shared)
+
+	_, err = MergeDeltas("Test delta", "test",
+		&humanitec.CreateDeploymentDeltaRequest{Shared: []humanitec.UpdateAction{dnsAction}},
+		&humanitec.CreateDeploymentDeltaRequest{Shared: []humanitec.UpdateAction{{
+			Operation: "add",
+			Path:      "/dns",
+			Value:     map[string]interface{}{"type": "dns", "class": "internal"},
+		}}},
+	)
+	assert.ErrorContains(t, err, "conflicting definitions for shared resource 'dns'")
+
+	_, err = MergeDeltas("Test delta", "test",
+		&humanitec.CreateDeploymentDeltaRequest{Modules: humanitec.ModuleDeltas{Add: map[string]map[string]interface{}{"backend": {}}}},
+		&humanitec.CreateDeploymentDeltaRequest{Modules: humanitec.ModuleDeltas{Add: map[string]map[string]interface{}{"backend": {}}}},
+	)
+	assert.ErrorContains(t, err, "duplicate module 'backend'")
+}
+
+func TestGetProbeDetails(t *testing.T) {
+	var tests = []struct {
+		Name     string
+		Probe    *ContainerProbe
+		Output   map[string]interface{}
+		Warnings []Warning
+		Error    error
+	}{
+		{
+			Name: "Should convert httpGet probe",
+			Probe: &ContainerProbe{
+				HttpGet: &score.HttpProbe{
+					Path:        "/health",
+					Port:        8080,
+					HttpHeaders: []score.HttpProbeHttpHeadersElem{{Name: Ref("X-Test"), Value: Ref("yes")}},
+				},
+			},
+			Output: map[string]interface{}{
+				"type":    "http",
+				"path":    "/health",
+				"port":    8080,
+				"headers": map[string]string{"X-Test": "yes"},
+			},
+		},
+		{
+			Name: "Should convert exec probe with timings",
+			Probe: &ContainerProbe{
+				Exec:                &ExecProbe{Command: []string{"/bin/sh", "-c", "pg_isready"}},
+				InitialDelaySeconds: Ref(5),
+				PeriodSeconds:       Ref(10),
+				TimeoutSeconds:      Ref(2),
+				SuccessThreshold:    Ref(1),
+				FailureThreshold:    Ref(3),
+			},
+			Output: map[string]interface{}{
+				"type":                  "command",
+				"command":               []string{"/bin/sh", "-c", "pg_isready"},
+				"initial_delay_seconds": 5,
+				"period_seconds":        10,
+				"timeout_seconds":       2,
+				"success_threshold":     1,
+				"failure_threshold":     3,
+			},
+		},
+		{
+			Name: "Should convert tcpSocket probe",
+			Probe: &ContainerProbe{
+				TcpSocket: &TcpSocketProbe{Port: 5432},
+			},
+			Output: map[string]interface{}{
+				"type": "tcp",
+				"port": 5432,
+			},
+		},
+		{
+			Name:  "Should reject probe without handlers",
+			Probe: &ContainerProbe{PeriodSeconds: Ref(10)},
+			Error: errors.New("probe must have one of httpGet, exec or tcpSocket handlers"),
+		},
+		{
+			Name: "Should reject probe with several handlers",
+			Probe: &ContainerProbe{
+				Exec:      &ExecProbe{Command: []string{"true"}},
+				TcpSocket: &TcpSocketProbe{Port: 5432},
+			},
+			Error: errors.New("probe must have only one of httpGet, exec or tcpSocket handlers"),
+		},
+		{
+			Name:     "Should skip httpGet probe without path",
+			Probe:    &ContainerProbe{HttpGet: &score.HttpProbe{Port: 8080}},
+			Warnings: []Warning{{Message: "httpGet probe is missing the path and will be ignored."}},
+		},
+		{
+			Name:  "Should reject exec probe without command",
+			Probe: &ContainerProbe{Exec: &ExecProbe{}},
+			Error: errors.New("exec probe is missing the command"),
+		},
+		{
+			Name:  "Should reject tcpSocket probe without port",
+			Probe: &ContainerProbe{TcpSocket: &TcpSocketProbe{}},
+			Error: errors.New("tcpSocket probe is missing the port"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			ctx, _ := buildContext(score.WorkloadMetadata{}, nil, nil)
+			res, err := getProbeDetails(tt.Probe, ctx)
+
+			if tt.Error != nil {
+				// On Error
+				//
+				assert.ErrorContains(t, err, tt.Error.Error())
+			} else {
+				// On Success
+				//
+				assert.NoError(t, err)
+				assert.Equal(t, tt.Output, res)
+				if tt.Warnings == nil {
+					tt.Warnings = []Warning{}
+				}
+				assert.Equal(t, tt.Warnings, ctx.Warnings())
+			}
+		})
+	}
+}
+
+func TestScoreConvertProbes(t *testing.T) {
+	var source = WorkloadSource{
+		Spec: &score.Workload{
+			Metadata: score.WorkloadMetadata{
+				"name": "test",
+			},
+			Containers: score.WorkloadContainers{
+				"backend": score.Container{
+					Image: "busybox",
+					ReadinessProbe: &score.ContainerProbe{
+						HttpGet: score.HttpProbe{Path: "/health", Port: 8080},
+					},
+				},
+				"sidecar": score.Container{
+					Image: "busybox",
+				},
+			},
+		},
+		Extensions: &extensions.HumanitecExtensionsSpec{},
+		Probes: map[string]ContainerProbes{
+			"backend": {
+				Liveness: &ContainerProbe{
+					Exec:          &ExecProbe{Command: []string{"true"}},
+					PeriodSeconds: Ref(30),
+				},
+			},
+			"sidecar": {
+				Readiness: &ContainerProbe{},
+			},
+		},
+	}
+
+	_, _, err := ConvertSpecs("Test delta", "test", "", []WorkloadSource{source}, false)
+	assert.ErrorContains(t, err, "processing container specification for 'sidecar': readiness probe: probe must have one of httpGet, exec or tcpSocket handlers")
+
+	delete(source.Probes, "sidecar")
+	res, _, err := ConvertSpecs("Test delta", "test", "", []WorkloadSource{source}, false)
+	assert.NoError(t, err)
+
+	var backend = res.Modules.Add["test"]["spec"].(map[string]interface{})["containers"].(map[string]interface{})["backend"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"type":           "command",
+		"command":        []string{"true"},
+		"period_seconds": 30,
+	}, backend["liveness_probe"])
+	assert.Equal(t, map[string]interface{}{
+		"type": "http",
+		"path": "/health",
+		"port": 8080,
+	}, backend["readiness_probe"])
+}
+
+func TestConvertSpecsWarnings(t *testing.T) {
+	var frontend = &score.Workload{
+		Metadata: score.WorkloadMetadata{
+			"name": "frontend",
+		},
+		Containers: score.WorkloadContainers{
+			"frontend": score.Container{
+				Image: "nginx",
+				Variables: map[string]string{
+					"BACKEND_URL": "http://${resources.backend.name}:${resources.backend.port}",
+					"DB_NAME":     "${resources.db.name}",
+				},
+			},
+		},
+		Resources: map[string]score.Resource{
+			"backend": {Type: "service"},
+		},
+	}
+	var worker = &score.Workload{
+		Metadata: score.WorkloadMetadata{
+			"name": "worker",
+		},
+		Containers: score.WorkloadContainers{
+			"worker": score.Container{
+				Image: "busybox",
+			},
+		},
+	}
+	var probes = map[string]ContainerProbes{
+		"worker": {Liveness: &ContainerProbe{HttpGet: &score.HttpProbe{Port: 8080}}},
+	}
+
+	_, warnings, err := ConvertSpecs("Test delta", "test", "", []WorkloadSource{
+		{Spec: frontend, Extensions: &extensions.HumanitecExtensionsSpec{}},
+		{Spec: worker, Extensions: &extensions.HumanitecExtensionsSpec{}, Probes: probes},
+	}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []Warning{
+		{Workload: "frontend", Message: "service 'backend' is not one of the converted workloads."},
+		{Workload: "worker", Message: "httpGet probe is missing the path and will be ignored."},
+		{Workload: "frontend", Message: "Can not resolve '${resources.db.name}' in containers.frontend.variables.DB_NAME."},
+	}, warnings)
+	assert.Equal(t, "workload 'frontend': service 'backend' is not one of the converted workloads.", warnings[0].String())
+}
+
+func TestConvertSpecsResourceIdOnly(t *testing.T) {
+	var spec = &score.Workload{
+		Metadata: score.WorkloadMetadata{
+			"name": "api",
+		},
+		Containers: score.WorkloadContainers{
+			"main": score.Container{
+				Image: "busybox",
+				Variables: map[string]string{
+					"DB_HOST": "${resources.postgres.host}",
+				},
+			},
+		},
+		Resources: map[string]score.Resource{
+			"postgres": {
+				Metadata: score.ResourceMetadata{
+					"annotations": map[string]interface{}{
+						AnnotationLabelResourceId: "db",
+					},
+				},
+				Type: "postgres",
+			},
+		},
+	}
+
+	// A resource ID only reference is an external resource of the workload
+	res, _, err := ConvertSpecs("Test delta", "test", "", []WorkloadSource{
+		{Spec: spec, Extensions: &extensions.HumanitecExtensionsSpec{}},
+	}, true)
+	assert.NoError(t, err)
+	var module = res.Modules.Add["api"]
+	assert.Equal(t, map[string]interface{}{
+		"db": map[string]interface{}{"type": "postgres", "class": "default"},
+	}, module["externals"])
+	assert.Equal(t, map[string]interface{}{
+		"DB_HOST": "${externals.db.host}",
+	}, module["spec"].(map[string]interface{})["containers"].(map[string]interface{})["main"].(map[string]interface{})["variables"])
+	assert.Empty(t, res.Shared)
+}
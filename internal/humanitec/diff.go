@@ -0,0 +1,68 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Change describes a single difference between two documents.
+// Before is nil for added values, and After is nil for removed values.
+type Change struct {
+	Path   string
+	Before interface{}
+	After  interface{}
+}
+
+// Operation returns the JSON Patch (RFC 6902) operation matching the change.
+func (c *Change) Operation() string {
+	switch {
+	case c.Before == nil:
+		return "add"
+	case c.After == nil:
+		return "remove"
+	default:
+		return "replace"
+	}
+}
+
+// Diff compares two documents and returns the list of changes ordered by path.
+// Maps are compared key by key recursively, while any other values (including lists) are compared as a whole.
+func Diff(before, after interface{}) []Change {
+	return diffValues(nil, before, after)
+}
+
+func diffValues(path []string, before, after interface{}) []Change {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if !beforeIsMap || !afterIsMap {
+		if reflect.DeepEqual(before, after) {
+			return nil
+		}
+		return []Change{{Path: joinPointer(path...), Before: before, After: after}}
+	}
+
+	var keys = make([]string, 0, len(beforeMap)+len(afterMap))
+	for key := range beforeMap {
+		keys = append(keys, key)
+	}
+	for key := range afterMap {
+		if _, exists := beforeMap[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []Change
+	for _, key := range keys {
+		var subPath = append(append(make([]string, 0, len(path)+1), path...), key)
+		changes = append(changes, diffValues(subPath, beforeMap[key], afterMap[key])...)
+	}
+	return changes
+}
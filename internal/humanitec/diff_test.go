@@ -0,0 +1,50 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	var before = map[string]interface{}{
+		"image": "busybox",
+		"args":  []interface{}{"-c"},
+		"variables": map[string]interface{}{
+			"DEBUG": "true",
+			"PORT":  "80",
+		},
+		"files/path": "old",
+	}
+	var after = map[string]interface{}{
+		"image": "nginx",
+		"args":  []interface{}{"-c"},
+		"variables": map[string]interface{}{
+			"PORT":  "80",
+			"DEBUG": nil,
+			"HOST":  "localhost",
+		},
+	}
+
+	var changes = Diff(before, after)
+	assert.Equal(t, []Change{
+		{Path: "/files~1path", Before: "old", After: nil},
+		{Path: "/image", Before: "busybox", After: "nginx"},
+		{Path: "/variables/DEBUG", Before: "true", After: nil},
+		{Path: "/variables/HOST", Before: nil, After: "localhost"},
+	}, changes)
+
+	assert.Equal(t, "remove", changes[0].Operation())
+	assert.Equal(t, "replace", changes[1].Operation())
+	assert.Equal(t, "add", changes[3].Operation())
+
+	assert.Empty(t, Diff(before, before))
+	assert.Equal(t, []Change{{Path: "", Before: nil, After: after}}, Diff(nil, after))
+}
@@ -0,0 +1,558 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	score "github.com/score-spec/score-go/types"
+)
+
+const (
+	scoreApiVersion      = "score.dev/v1b1"
+	extensionsApiVersion = "humanitec.org/v1b1"
+
+	// importedEnvResource is the name of the 'environment' resource used for '${values...}' references
+	importedEnvResource = "env"
+)
+
+// escapedPlaceholderRegEx matches the '${...}' templates escaped by the files 'noExpand' option.
+var escapedPlaceholderRegEx = regexp.MustCompile(`\$\\({[a-zA-Z0-9.\-_\[\]"'#]+})`)
+
+// ImportedWorkload is a SCORE specification generated from a Humanitec module.
+type ImportedWorkload struct {
+	ApiVersion string                 `yaml:"apiVersion"`
+	Metadata   map[string]interface{} `yaml:"metadata"`
+	Service    map[string]interface{} `yaml:"service,omitempty"`
+	Containers map[string]interface{} `yaml:"containers"`
+	Resources  map[string]interface{} `yaml:"resources,omitempty"`
+}
+
+// ImportedExtensions is a Humanitec extensions specification generated from a Humanitec module.
+type ImportedExtensions struct {
+	ApiVersion string                 `yaml:"apiVersion"`
+	Profile    string                 `yaml:"profile,omitempty"`
+	Spec       map[string]interface{} `yaml:"spec,omitempty"`
+}
+
+// ImportResult is the result of a Humanitec module reverse conversion.
+type ImportResult struct {
+	Workload *ImportedWorkload
+	// Extensions is nil if the module is fully expressed by the SCORE specification.
+	Extensions *ImportedExtensions
+	// Lossy lists the module fields that can not be expressed, or only approximately, by the generated specifications.
+	Lossy []string
+}
+
+// importer keeps track of the SCORE resources required by the references found in a Humanitec module.
+type importer struct {
+	externals map[string]interface{}
+	shared    map[string]interface{}
+	resources map[string]interface{}
+	lossy     []string
+
+	// names maps the shared and foreign modules resources IDs to the SCORE resources names
+	names map[string]string
+}
+
+// ImportModule converts the Humanitec module into an equivalent SCORE specification, reversing ConvertSpec.
+// The shared resources of the deployment set are used to resolve the '${shared...}' references.
+// The module fields that SCORE can not express are moved to the extensions specification when possible.
+func ImportModule(name string, module map[string]interface{}, shared map[string]interface{}) (*ImportResult, error) {
+	spec, ok := module["spec"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("module '%s' has no spec", name)
+	}
+	var im = importer{
+		externals: asMap(module["externals"]),
+		shared:    shared,
+		resources: map[string]interface{}{},
+		names:     map[string]string{},
+	}
+	for key := range module {
+		switch key {
+		case "profile", "spec", "externals":
+		default:
+			im.lossyf(key, "not supported")
+		}
+	}
+
+	var workload = ImportedWorkload{
+		ApiVersion: scoreApiVersion,
+		Metadata:   map[string]interface{}{"name": name},
+		Containers: map[string]interface{}{},
+	}
+	var extSpec = map[string]interface{}{}
+
+	for key, val := range spec {
+		var location = "spec." + key
+		switch key {
+		case "annotations":
+			var annotations = map[string]interface{}{}
+			for aKey, aVal := range asMap(val) {
+				if aKey != managedByAnnotation && aKey != workloadSourceAnnotation && aKey != sharedResourcesAnnotation && aKey != ownedPathsAnnotation {
+					annotations[aKey] = im.reverseValue(joinLocation(location, aKey), aVal)
+				}
+			}
+			if len(annotations) > 0 {
+				extSpec["annotations"] = annotations
+			}
+
+		case "containers":
+			var containersExt = map[string]interface{}{}
+			for cName, cVal := range asMap(val) {
+				var cExt = map[string]interface{}{}
+				workload.Containers[cName] = im.importContainer(joinLocation(location, cName), cName, asMap(cVal), cExt)
+				if len(cExt) > 0 {
+					containersExt[cName] = cExt
+				}
+			}
+			if len(containersExt) > 0 {
+				extSpec["containers"] = containersExt
+			}
+
+		case "service":
+			var serviceExt = map[string]interface{}{}
+			for sKey, sVal := range asMap(val) {
+				if sKey == "ports" {
+					workload.Service = map[string]interface{}{"ports": im.importPorts(joinLocation(location, sKey), asMap(sVal))}
+				} else {
+					serviceExt[sKey] = im.reverseValue(joinLocation(location, sKey), sVal)
+				}
+			}
+			if len(serviceExt) > 0 {
+				extSpec["service"] = serviceExt
+			}
+
+		default:
+			extSpec[key] = im.reverseValue(location, val)
+		}
+	}
+
+	// Declare the remaining externals, even if not referenced
+	for resName := range im.externals {
+		im.addExternal(resName)
+	}
+	if len(im.resources) > 0 {
+		workload.Resources = im.resources
+	}
+
+	var res = ImportResult{Workload: &workload}
+	var profile, _ = module["profile"].(string)
+	if (profile != "" && profile != DefaultWorkloadProfile) || len(extSpec) > 0 {
+		res.Extensions = &ImportedExtensions{ApiVersion: extensionsApiVersion, Spec: extSpec}
+		if profile != DefaultWorkloadProfile {
+			res.Extensions.Profile = profile
+		}
+	}
+	sort.Strings(im.lossy)
+	res.Lossy = im.lossy
+
+	return &res, nil
+}
+
+// lossyf records a module field that can not be fully expressed.
+func (im *importer) lossyf(location, format string, args ...interface{}) {
+	im.lossy = append(im.lossy, fmt.Sprintf("%s: %s", location, fmt.Sprintf(format, args...)))
+}
+
+// importContainer reverses convertContainerSpec.
+// The container fields SCORE does not support are added to the ext extensions map.
+func (im *importer) importContainer(location, name string, container, ext map[string]interface{}) map[string]interface{} {
+	var res = map[string]interface{}{}
+	for key, val := range container {
+		var keyLocation = joinLocation(location, key)
+		switch key {
+		case "id":
+			if val != name {
+				im.lossyf(keyLocation, "container id '%v' is replaced with '%s'", val, name)
+			}
+
+		case "image", "command", "args":
+			res[key] = val
+
+		case "variables":
+			var variables = map[string]interface{}{}
+			for vKey, vVal := range asMap(val) {
+				str, ok := vVal.(string)
+				if !ok {
+					im.lossyf(joinLocation(keyLocation, vKey), "%T value converted to a string", vVal)
+					str = fmt.Sprint(vVal)
+				}
+				variables[vKey] = im.reverse(joinLocation(keyLocation, vKey), str)
+			}
+			res["variables"] = variables
+
+		case "resources":
+			var resources = map[string]interface{}{}
+			for rKey, rVal := range asMap(val) {
+				if rKey != "limits" && rKey != "requests" {
+					im.lossyf(joinLocation(keyLocation, rKey), "not supported")
+					continue
+				}
+				var values = map[string]interface{}{}
+				for vKey, vVal := range asMap(rVal) {
+					if vKey == "cpu" || vKey == "memory" {
+						values[vKey] = fmt.Sprint(vVal)
+					} else {
+						im.lossyf(joinLocation(joinLocation(keyLocation, rKey), vKey), "not supported")
+					}
+				}
+				resources[rKey] = values
+			}
+			res["resources"] = resources
+
+		case "liveness_probe":
+			if probe := im.importProbe(keyLocation, asMap(val)); probe != nil {
+				res["livenessProbe"] = probe
+			}
+
+		case "readiness_probe":
+			if probe := im.importProbe(keyLocation, asMap(val)); probe != nil {
+				res["readinessProbe"] = probe
+			}
+
+		case "files":
+			var files = asMap(val)
+			var list = make([]interface{}, 0, len(files))
+			for _, target := range sortedKeys(files) {
+				list = append(list, im.importFile(joinLocation(keyLocation, target), target, asMap(files[target])))
+			}
+			res["files"] = list
+
+		case "volume_mounts":
+			var mounts = asMap(val)
+			var list = make([]interface{}, 0, len(mounts))
+			for _, target := range sortedKeys(mounts) {
+				list = append(list, im.importVolume(joinLocation(keyLocation, target), target, asMap(mounts[target])))
+			}
+			res["volumes"] = list
+
+		default:
+			ext[key] = im.reverseValue(keyLocation, val)
+		}
+	}
+	return res
+}
+
+// importProbe reverses getProbeDetails into an extended probe specification (see ExtractProbes).
+func (im *importer) importProbe(location string, probe map[string]interface{}) map[string]interface{} {
+	var res = map[string]interface{}{}
+	switch probe["type"] {
+	case "http":
+		var httpGet = map[string]interface{}{
+			"path": probe["path"],
+			"port": toInt(probe["port"]),
+		}
+		if headers := asMap(probe["headers"]); len(headers) > 0 {
+			var list = make([]interface{}, 0, len(headers))
+			for _, hName := range sortedKeys(headers) {
+				list = append(list, map[string]interface{}{"name": hName, "value": headers[hName]})
+			}
+			httpGet["httpHeaders"] = list
+		}
+		res["httpGet"] = httpGet
+	case "command":
+		res["exec"] = map[string]interface{}{"command": probe["command"]}
+	case "tcp":
+		res["tcpSocket"] = map[string]interface{}{"port": toInt(probe["port"])}
+	default:
+		im.lossyf(location, "probe type '%v' not supported", probe["type"])
+		return nil
+	}
+
+	for key, val := range probe {
+		switch key {
+		case "type", "path", "port", "headers", "command":
+		case "initial_delay_seconds":
+			res["initialDelaySeconds"] = toInt(val)
+		case "period_seconds":
+			res["periodSeconds"] = toInt(val)
+		case "timeout_seconds":
+			res["timeoutSeconds"] = toInt(val)
+		case "success_threshold":
+			res["successThreshold"] = toInt(val)
+		case "failure_threshold":
+			res["failureThreshold"] = toInt(val)
+		default:
+			im.lossyf(joinLocation(location, key), "not supported")
+		}
+	}
+	return res
+}
+
+// importFile reverses convertFileMountSpec. The file content is always inlined.
+func (im *importer) importFile(location, target string, file map[string]interface{}) map[string]interface{} {
+	var res = map[string]interface{}{"target": target}
+	for key, val := range file {
+		switch key {
+		case "mode":
+			if mode, _ := val.(string); mode != "" {
+				res["mode"] = mode
+			}
+		case "value":
+			var content = fmt.Sprint(val)
+			if escapedPlaceholderRegEx.MatchString(content) {
+				res["content"] = escapedPlaceholderRegEx.ReplaceAllString(content, "$$$1")
+				res["noExpand"] = true
+			} else {
+				res["content"] = im.reverse(joinLocation(location, key), content)
+			}
+		default:
+			im.lossyf(joinLocation(location, key), "not supported")
+		}
+	}
+	return res
+}
+
+// importVolume reverses the containers volumes conversion.
+func (im *importer) importVolume(location, target string, mount map[string]interface{}) map[string]interface{} {
+	var res = map[string]interface{}{"target": target}
+	for key, val := range mount {
+		switch key {
+		case "id":
+			var id = fmt.Sprint(val)
+			if ref := im.reverseRef(joinLocation(location, key), id); ref != "" {
+				res["source"] = fmt.Sprintf("${%s}", ref)
+			} else {
+				res["source"] = im.reverse(joinLocation(location, key), id)
+			}
+		case "sub_path":
+			if path, _ := val.(string); path != "" {
+				res["path"] = path
+			}
+		case "read_only":
+			if readOnly, _ := val.(bool); readOnly {
+				res["readOnly"] = true
+			}
+		default:
+			im.lossyf(joinLocation(location, key), "not supported")
+		}
+	}
+	return res
+}
+
+// importPorts reverses the service ports conversion.
+func (im *importer) importPorts(location string, ports map[string]interface{}) map[string]interface{} {
+	var res = map[string]interface{}{}
+	for pName, pVal := range ports {
+		var pLocation = joinLocation(location, pName)
+		var port = map[string]interface{}{}
+		var spec = asMap(pVal)
+		for key, val := range spec {
+			switch key {
+			case "service_port":
+				port["port"] = toInt(val)
+			case "container_port":
+				if toInt(val) != toInt(spec["service_port"]) {
+					port["targetPort"] = toInt(val)
+				}
+			case "protocol":
+				if val != string(score.ServicePortProtocolTCP) {
+					port["protocol"] = val
+				}
+			default:
+				im.lossyf(joinLocation(pLocation, key), "not supported")
+			}
+		}
+		res[pName] = port
+	}
+	return res
+}
+
+// reverseValue reverses the '${...}' references in all the string values and map keys, at any depth.
+func (im *importer) reverseValue(location string, val interface{}) interface{} {
+	switch v := val.(type) {
+	case string:
+		return im.reverse(location, v)
+	case map[string]interface{}:
+		var res = make(map[string]interface{}, len(v))
+		for key, item := range v {
+			res[im.reverse(location, key)] = im.reverseValue(joinLocation(location, key), item)
+		}
+		return res
+	case []interface{}:
+		var res = make([]interface{}, len(v))
+		for i, item := range v {
+			res[i] = im.reverseValue(fmt.Sprintf("%s[%d]", location, i), item)
+		}
+		return res
+	default:
+		return val
+	}
+}
+
+// reverse replaces the Humanitec '${...}' references in the string with the matching SCORE references.
+// Other references are kept as is, while the '$$' sequences are escaped.
+func (im *importer) reverse(location, src string) string {
+	return placeholderRegEx.ReplaceAllStringFunc(src, func(str string) string {
+		var matches = placeholderRegEx.FindStringSubmatch(str)
+		if matches[2] == "" {
+			return "$$$$"
+		}
+		if ref := im.reverseRef(location, matches[2]); ref != "" {
+			return fmt.Sprintf("${%s}", ref)
+		}
+		return str
+	})
+}
+
+// reverseRef maps the Humanitec reference to a SCORE resource reference, declaring the resource as needed.
+// Returns an empty string if the reference is not a Humanitec resource reference.
+func (im *importer) reverseRef(location, ref string) string {
+	var segments = strings.Split(ref, ".")
+	var resName string
+	var props []string
+	switch {
+	case segments[0] == "values" && len(segments) > 1:
+		resName, props = importedEnvResource, segments[1:]
+		if _, exists := im.resources[resName]; !exists {
+			im.resources[resName] = map[string]interface{}{"type": "environment"}
+		}
+
+	case segments[0] == "externals" && len(segments) > 1:
+		resName, props = segments[1], segments[2:]
+		im.addExternal(resName)
+
+	case segments[0] == "shared" && len(segments) > 1:
+		resName, props = im.addShared(location, segments[1]), segments[2:]
+
+	case segments[0] == "modules" && len(segments) > 2 && segments[2] == "service":
+		resName, props = segments[1], segments[3:]
+		if _, exists := im.resources[resName]; !exists {
+			im.resources[resName] = map[string]interface{}{"type": "service"}
+		}
+
+	case segments[0] == "modules" && len(segments) > 3 && segments[2] == "externals":
+		var resId = strings.Join(segments[:4], ".")
+		props = segments[4:]
+		var exists bool
+		if resName, exists = im.names[resId]; !exists {
+			resName = im.uniqueName(segments[3], segments[1]+"-"+segments[3])
+			im.names[resId] = resName
+			im.lossyf(location, "the type of the '%s' resource is unknown", resId)
+			im.resources[resName] = map[string]interface{}{
+				"type":     "unknown",
+				"metadata": resIdAnnotation(resId),
+			}
+		}
+
+	default:
+		return ""
+	}
+
+	return strings.Join(append([]string{"resources", resName}, props...), ".")
+}
+
+// addExternal declares the SCORE resource for the module external resource.
+func (im *importer) addExternal(resName string) {
+	if _, exists := im.resources[resName]; exists {
+		return
+	}
+	var location = joinLocation("externals", resName)
+	ext, ok := im.externals[resName].(map[string]interface{})
+	if !ok {
+		im.lossyf(location, "the type of the resource is unknown, it is not declared by the module")
+		im.resources[resName] = map[string]interface{}{"type": "unknown"}
+		return
+	}
+	im.resources[resName] = im.importResource(location, ext)
+}
+
+// addShared declares the SCORE resource for the shared resource, and returns its name.
+// Shared resources with a non default class are named '{name}-class-{class}' by the conversion.
+func (im *importer) addShared(location, sharedName string) string {
+	var resName, resId = sharedName, "shared." + sharedName
+	shared, ok := im.shared[sharedName].(map[string]interface{})
+	if ok {
+		if id, _ := shared["id"].(string); strings.HasPrefix(id, "shared.") {
+			resName, resId = strings.TrimPrefix(id, "shared."), id
+		}
+	}
+	if name, exists := im.names[resId]; exists {
+		return name
+	}
+	resName = im.uniqueName(resName, resName+"-shared")
+	im.names[resId] = resName
+
+	var res map[string]interface{}
+	if ok {
+		res = im.importResource(joinLocation("shared", sharedName), shared)
+	} else {
+		im.lossyf(location, "the type of the '%s' resource is unknown, it is not declared by the deployment set", resId)
+		res = map[string]interface{}{"type": "unknown"}
+	}
+	res["metadata"] = resIdAnnotation(resId)
+	im.resources[resName] = res
+	return resName
+}
+
+// uniqueName returns the name, or the alternative name if the name is already used by another resource.
+// The module externals names are reserved, as they are used as is.
+func (im *importer) uniqueName(name, alt string) string {
+	if _, isExternal := im.externals[name]; isExternal {
+		return alt
+	}
+	if _, exists := im.resources[name]; exists {
+		return alt
+	}
+	return name
+}
+
+// importResource reverses an external or shared resource definition.
+func (im *importer) importResource(location string, def map[string]interface{}) map[string]interface{} {
+	var res = map[string]interface{}{}
+	for key, val := range def {
+		switch key {
+		case "type":
+			res["type"] = val
+		case "class":
+			if val != "default" {
+				res["class"] = val
+			}
+		case "params":
+			res["params"] = im.reverseValue(joinLocation(location, key), val)
+		case "id":
+		default:
+			im.lossyf(joinLocation(location, key), "not supported")
+		}
+	}
+	return res
+}
+
+// resIdAnnotation returns the resource metadata with the Humanitec resource ID annotation.
+func resIdAnnotation(resId string) map[string]interface{} {
+	return map[string]interface{}{
+		"annotations": map[string]interface{}{AnnotationLabelResourceId: resId},
+	}
+}
+
+func asMap(val interface{}) map[string]interface{} {
+	res, _ := val.(map[string]interface{})
+	return res
+}
+
+// toInt converts JSON numbers to integers. Other values are returned as is.
+func toInt(val interface{}) interface{} {
+	if num, ok := val.(float64); ok && num == float64(int(num)) {
+		return int(num)
+	}
+	return val
+}
+
+func sortedKeys(src map[string]interface{}) []string {
+	var keys = make([]string, 0, len(src))
+	for key := range src {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
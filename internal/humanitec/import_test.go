@@ -0,0 +1,235 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestImportModule(t *testing.T) {
+	var module = map[string]interface{}{
+		"profile": "humanitec/default-module",
+		"spec": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				managedByAnnotation:    managedBy,
+				"prometheus.io/scrape": "true",
+			},
+			"containers": map[string]interface{}{
+				"backend": map[string]interface{}{
+					"id":    "backend",
+					"image": "busybox:latest",
+					"variables": map[string]interface{}{
+						"DB_HOST":  "${externals.db.host}",
+						"DNS":      "${shared.dns.host}",
+						"API_URL":  "http://${modules.api.service.name}:8080",
+						"DEBUG":    "${values.DEBUG}",
+						"APP_ID":   "${context.app.id}",
+						"REPLICAS": float64(2),
+						"PRICE":    "$$5",
+					},
+					"resources": map[string]interface{}{
+						"limits": map[string]interface{}{"cpu": "500m", "memory": "128Mi"},
+					},
+					"liveness_probe": map[string]interface{}{
+						"type": "http", "path": "/alive", "port": float64(8080),
+						"headers":        map[string]interface{}{"X-Probe": "liveness"},
+						"period_seconds": float64(10),
+					},
+					"readiness_probe": map[string]interface{}{"type": "tcp", "port": float64(8080)},
+					"files": map[string]interface{}{
+						"/etc/backend/config.yaml": map[string]interface{}{"mode": "0644", "value": "db: ${externals.db.name}"},
+						"/etc/backend/raw.txt":     map[string]interface{}{"mode": "", "value": "name: $\\{metadata.name}"},
+					},
+					"volume_mounts": map[string]interface{}{
+						"/data": map[string]interface{}{"id": "externals.data", "sub_path": "cache", "read_only": true},
+					},
+					"security_context": map[string]interface{}{"runAsUser": float64(1000)},
+				},
+			},
+			"service": map[string]interface{}{
+				"ports": map[string]interface{}{
+					"www": map[string]interface{}{"protocol": "TCP", "service_port": float64(80), "container_port": float64(8080)},
+				},
+			},
+			"ingress": map[string]interface{}{"host": "${externals.dns.host}", "tls": "${shared.dns.host}"},
+		},
+		"externals": map[string]interface{}{
+			"db":   map[string]interface{}{"type": "postgres", "class": "default"},
+			"data": map[string]interface{}{"type": "volume", "class": "default"},
+			"dns":  map[string]interface{}{"type": "dns", "class": "default", "params": map[string]interface{}{"subdomain": "${values.PREFIX}-app"}},
+		},
+		"deploy": map[string]interface{}{"when": "before"},
+	}
+	var shared = map[string]interface{}{
+		"dns": map[string]interface{}{"type": "dns", "class": "default"},
+	}
+
+	res, err := ImportModule("backend", module, shared)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"image": "busybox:latest",
+		"variables": map[string]interface{}{
+			"DB_HOST":  "${resources.db.host}",
+			"DNS":      "${resources.dns-shared.host}",
+			"API_URL":  "http://${resources.api.name}:8080",
+			"DEBUG":    "${resources.env.DEBUG}",
+			"APP_ID":   "${context.app.id}",
+			"REPLICAS": "2",
+			"PRICE":    "$$$$5",
+		},
+		"resources": map[string]interface{}{
+			"limits": map[string]interface{}{"cpu": "500m", "memory": "128Mi"},
+		},
+		"livenessProbe": map[string]interface{}{
+			"httpGet": map[string]interface{}{
+				"path":        "/alive",
+				"port":        8080,
+				"httpHeaders": []interface{}{map[string]interface{}{"name": "X-Probe", "value": "liveness"}},
+			},
+			"periodSeconds": 10,
+		},
+		"readinessProbe": map[string]interface{}{"tcpSocket": map[string]interface{}{"port": 8080}},
+		"files": []interface{}{
+			map[string]interface{}{"target": "/etc/backend/config.yaml", "mode": "0644", "content": "db: ${resources.db.name}"},
+			map[string]interface{}{"target": "/etc/backend/raw.txt", "content": "name: ${metadata.name}", "noExpand": true},
+		},
+		"volumes": []interface{}{
+			map[string]interface{}{"target": "/data", "source": "${resources.data}", "path": "cache", "readOnly": true},
+		},
+	}, res.Workload.Containers["backend"])
+	assert.Equal(t, map[string]interface{}{
+		"ports": map[string]interface{}{"www": map[string]interface{}{"port": 80, "targetPort": 8080}},
+	}, res.Workload.Service)
+	assert.Equal(t, map[string]interface{}{
+		"db":   map[string]interface{}{"type": "postgres"},
+		"data": map[string]interface{}{"type": "volume"},
+		"dns": map[string]interface{}{
+			"type":   "dns",
+			"params": map[string]interface{}{"subdomain": "${resources.env.PREFIX}-app"},
+		},
+		"dns-shared": map[string]interface{}{
+			"type":     "dns",
+			"metadata": map[string]interface{}{"annotations": map[string]interface{}{AnnotationLabelResourceId: "shared.dns"}},
+		},
+		"api": map[string]interface{}{"type": "service"},
+		"env": map[string]interface{}{"type": "environment"},
+	}, res.Workload.Resources)
+	assert.Equal(t, &ImportedExtensions{
+		ApiVersion: "humanitec.org/v1b1",
+		Spec: map[string]interface{}{
+			"annotations": map[string]interface{}{"prometheus.io/scrape": "true"},
+			"containers": map[string]interface{}{
+				"backend": map[string]interface{}{"security_context": map[string]interface{}{"runAsUser": float64(1000)}},
+			},
+			"ingress": map[string]interface{}{"host": "${resources.dns.host}", "tls": "${resources.dns-shared.host}"},
+		},
+	}, res.Extensions)
+	assert.Equal(t, []string{
+		"deploy: not supported",
+		"spec.containers.backend.variables.REPLICAS: float64 value converted to a string",
+	}, res.Lossy)
+}
+
+func TestImportModule_roundTrip(t *testing.T) {
+	const spec = `apiVersion: score.dev/v1b1
+metadata:
+  name: backend
+service:
+  ports:
+    www:
+      port: 80
+      targetPort: 8080
+    metrics:
+      port: 9090
+      protocol: UDP
+containers:
+  backend:
+    image: busybox:latest
+    command: ["/bin/sh"]
+    args: ["-c", "sleep 3600"]
+    variables:
+      DB_HOST: ${resources.db.host}
+      DB_NAME: postgres://${resources.db.user}@${resources.db.host}/${metadata.name}
+      DNS: ${resources.dns.host}
+    livenessProbe:
+      exec:
+        command: ["/bin/true"]
+      initialDelaySeconds: 5
+    readinessProbe:
+      httpGet:
+        path: /ready
+        port: 8080
+    resources:
+      requests:
+        cpu: 100m
+    files:
+      - target: /etc/config.txt
+        mode: "0600"
+        content: "user: ${resources.db.user}"
+      - target: /etc/raw.txt
+        content: "raw: ${resources.db.user}"
+        noExpand: true
+    volumes:
+      - source: ${resources.data}
+        target: /data
+resources:
+  db:
+    type: postgres
+    class: large
+  data:
+    type: volume
+  dns:
+    type: dns
+    metadata:
+      annotations:
+        score.humanitec.io/resId: shared.dns
+`
+	const ext = `apiVersion: humanitec.org/v1b1
+profile: humanitec/worker
+spec:
+  labels:
+    team: ${resources.dns.host}
+`
+
+	workload, err := LoadWorkload(LoadOptions{Spec: []byte(spec), Extensions: []byte(ext)})
+	assert.NoError(t, err)
+	delta, _, err := ConvertSpecs("test", "development", "", []WorkloadSource{*workload}, true)
+	assert.NoError(t, err)
+
+	var module map[string]interface{}
+	assert.NoError(t, normalize(delta.Modules.Add["backend"], &module))
+	var shared = map[string]interface{}{}
+	for _, action := range delta.Shared {
+		var val interface{}
+		assert.NoError(t, normalize(action.Value, &val))
+		shared[action.Path[1:]] = val
+	}
+
+	res, err := ImportModule("backend", module, shared)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Lossy)
+
+	// Convert the imported specifications again
+	rawSpec, err := yaml.Marshal(res.Workload)
+	assert.NoError(t, err)
+	rawExt, err := yaml.Marshal(res.Extensions)
+	assert.NoError(t, err)
+	imported, err := LoadWorkload(LoadOptions{Spec: rawSpec, Extensions: rawExt})
+	assert.NoError(t, err)
+	reconverted, _, err := ConvertSpecs("test", "development", "", []WorkloadSource{*imported}, true)
+	assert.NoError(t, err)
+
+	expected, _ := json.Marshal(delta)
+	actual, _ := json.Marshal(reconverted)
+	assert.JSONEq(t, string(expected), string(actual))
+}
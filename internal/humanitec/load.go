@@ -0,0 +1,192 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/imdario/mergo"
+	"github.com/mitchellh/mapstructure"
+	"github.com/tidwall/sjson"
+	yaml "gopkg.in/yaml.v3"
+
+	loader "github.com/score-spec/score-go/loader"
+	schema "github.com/score-spec/score-go/schema"
+	score "github.com/score-spec/score-go/types"
+
+	"github.com/score-spec/score-humanitec/internal/humanitec/extensions"
+)
+
+// discardLogger is used when no logger is set.
+var discardLogger = log.New(io.Discard, "", 0)
+
+// LoadOptions is the in-memory content of a SCORE workload specification and its customizations.
+type LoadOptions struct {
+	// Spec is the SCORE specification (YAML or JSON).
+	Spec []byte
+	// Overrides is the optional SCORE overrides specification, merged into the Spec.
+	Overrides []byte
+	// Properties are the optional 'path=value' overrides applied after the Overrides ('path' alone removes the property).
+	Properties []string
+	// Image replaces the containers images set to '.', if not empty.
+	Image string
+	// Extensions is the optional Humanitec extensions specification.
+	Extensions []byte
+
+	// BaseDir is the directory the containers files sources are relative to.
+	BaseDir string
+	// ReadFile reads the containers files sources. Defaults to os.ReadFile if not set.
+	ReadFile FileReader
+
+	// SkipValidation disables the SCORE specification schema validation.
+	SkipValidation bool
+
+	// Logger receives the loading and conversion progress messages. They are discarded if not set.
+	Logger *log.Logger
+}
+
+// LoadWorkload parses, customizes and validates the SCORE specification, ready to be converted with ConvertSpecs.
+// It does not access the file system.
+func LoadWorkload(opts LoadOptions) (*WorkloadSource, error) {
+	var logger = opts.Logger
+	if logger == nil {
+		logger = discardLogger
+	}
+
+	// Parse SCORE spec
+	//
+	logger.Print("Parsing SCORE spec...\n")
+	var srcMap map[string]interface{}
+	if err := loader.ParseYAML(&srcMap, bytes.NewReader(opts.Spec)); err != nil {
+		return nil, err
+	}
+
+	// Apply overrides (optional)
+	//
+	if len(opts.Overrides) > 0 {
+		logger.Print("Applying SCORE overrides...\n")
+		var ovrMap map[string]interface{}
+		if err := loader.ParseYAML(&ovrMap, bytes.NewReader(opts.Overrides)); err != nil {
+			return nil, err
+		}
+		if err := mergo.MergeWithOverwrite(&srcMap, ovrMap); err != nil {
+			return nil, fmt.Errorf("applying overrides: %w", err)
+		}
+	}
+
+	// Apply properties overrides (optional)
+	//
+	for _, pstr := range opts.Properties {
+		logger.Print("Applying SCORE properties overrides...\n")
+
+		jsonBytes, err := json.Marshal(srcMap)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling score spec: %w", err)
+		}
+
+		pmap := strings.SplitN(pstr, "=", 2)
+		if len(pmap) <= 1 {
+			var path = pmap[0]
+			logger.Printf("removing '%s'", path)
+			if jsonBytes, err = sjson.DeleteBytes(jsonBytes, path); err != nil {
+				return nil, fmt.Errorf("removing '%s': %w", path, err)
+			}
+		} else {
+			var path = pmap[0]
+			var val interface{}
+			if err := yaml.Unmarshal([]byte(pmap[1]), &val); err != nil {
+				val = pmap[1]
+			}
+
+			logger.Printf("overriding '%s' = '%s'", path, val)
+			if jsonBytes, err = sjson.SetBytes(jsonBytes, path, val); err != nil {
+				return nil, fmt.Errorf("overriding '%s': %w", path, err)
+			}
+		}
+
+		if err = json.Unmarshal(jsonBytes, &srcMap); err != nil {
+			return nil, fmt.Errorf("unmarshalling score spec: %w", err)
+		}
+	}
+
+	// Replace images defined by . (optional)
+	//
+	if opts.Image != "" {
+		if containers, ok := srcMap["containers"].(map[string]interface{}); ok {
+			for _, containerVal := range containers {
+				if container, ok := containerVal.(map[string]interface{}); ok {
+					if image, ok := container["image"].(string); ok && image == "." {
+						container["image"] = opts.Image
+					}
+				}
+			}
+		}
+	}
+
+	// Parse extensions (optional)
+	//
+	var extMap = make(map[string]interface{})
+	if len(opts.Extensions) > 0 {
+		logger.Print("Loading SCORE extensions...\n")
+		if err := yaml.Unmarshal(opts.Extensions, extMap); err != nil {
+			return nil, fmt.Errorf("parsing extensions: %w", err)
+		}
+	}
+
+	// Apply upgrades to fix backports or backward incompatible things
+	if changes, err := schema.ApplyCommonUpgradeTransforms(srcMap); err != nil {
+		return nil, fmt.Errorf("failed to upgrade spec: %w", err)
+	} else if len(changes) > 0 {
+		for _, change := range changes {
+			logger.Printf("Applying upgrade to specification: %s\n", change)
+		}
+	}
+
+	// Extract probes not supported by SCORE schema
+	//
+	probes, err := ExtractProbes(srcMap)
+	if err != nil {
+		return nil, fmt.Errorf("parsing probes: %w", err)
+	}
+
+	// Validate SCORE spec
+	//
+	if !opts.SkipValidation {
+		logger.Print("Validating SCORE spec...\n")
+		if err := schema.Validate(srcMap); err != nil {
+			return nil, fmt.Errorf("validating workload spec: %w", err)
+		}
+	}
+
+	// Convert SCORE spec
+	//
+	var spec score.Workload
+	logger.Print("Applying SCORE spec...\n")
+	if err = mapstructure.Decode(srcMap, &spec); err != nil {
+		return nil, fmt.Errorf("applying workload spec: %w", err)
+	}
+
+	var ext extensions.HumanitecExtensionsSpec
+	if err = mapstructure.Decode(extMap, &ext); err != nil {
+		return nil, fmt.Errorf("applying extensions spec: %w", err)
+	}
+
+	return &WorkloadSource{
+		BaseDir:    opts.BaseDir,
+		Spec:       &spec,
+		Extensions: &ext,
+		Probes:     probes,
+		ReadFile:   opts.ReadFile,
+		Logger:     opts.Logger,
+	}, nil
+}
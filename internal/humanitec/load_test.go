@@ -0,0 +1,148 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"errors"
+	"testing"
+
+	score "github.com/score-spec/score-go/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/score-spec/score-humanitec/internal/humanitec/extensions"
+)
+
+func TestLoadWorkload(t *testing.T) {
+	const spec = `apiVersion: score.dev/v1b1
+metadata:
+  name: backend
+containers:
+  main:
+    image: .
+    variables:
+      DEBUG: "false"
+      LOG_LEVEL: info
+`
+
+	var tests = []struct {
+		Name               string
+		Options            LoadOptions
+		ExpectedContainer  score.Container
+		ExpectedExtensions *extensions.HumanitecExtensionsSpec
+		ExpectedError      error
+	}{
+		// Success path
+		//
+		{
+			Name:    "Should load the spec as is",
+			Options: LoadOptions{Spec: []byte(spec)},
+			ExpectedContainer: score.Container{
+				Image:     ".",
+				Variables: map[string]string{"DEBUG": "false", "LOG_LEVEL": "info"},
+			},
+			ExpectedExtensions: &extensions.HumanitecExtensionsSpec{},
+		},
+		{
+			Name: "Should apply overrides, properties and image",
+			Options: LoadOptions{
+				Spec: []byte(spec),
+				Overrides: []byte(`containers:
+  main:
+    variables:
+      DEBUG: "true"
+`),
+				Properties: []string{"containers.main.variables.LOG_LEVEL", `containers.main.variables.PORT="8080"`},
+				Image:      "busybox:latest",
+				Extensions: []byte(`apiVersion: humanitec.org/v1b1
+profile: humanitec/default-module
+`),
+			},
+			ExpectedContainer: score.Container{
+				Image:     "busybox:latest",
+				Variables: map[string]string{"DEBUG": "true", "PORT": "8080"},
+			},
+			ExpectedExtensions: &extensions.HumanitecExtensionsSpec{
+				ApiVersion: "humanitec.org/v1b1",
+				Profile:    "humanitec/default-module",
+			},
+		},
+		// Errors handling
+		//
+		{
+			Name:          "Should report invalid specs",
+			Options:       LoadOptions{Spec: []byte("apiVersion: score.dev/v1b1\n")},
+			ExpectedError: errors.New("validating workload spec"),
+		},
+		{
+			Name:          "Should report invalid overrides",
+			Options:       LoadOptions{Spec: []byte(spec), Overrides: []byte("{NOT A VALID YAML")},
+			ExpectedError: errors.New("yaml"),
+		},
+		{
+			Name:          "Should report invalid extensions",
+			Options:       LoadOptions{Spec: []byte(spec), Extensions: []byte("{NOT A VALID YAML")},
+			ExpectedError: errors.New("parsing extensions"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			res, err := LoadWorkload(tt.Options)
+
+			if tt.ExpectedError != nil {
+				// On Error
+				//
+				assert.ErrorContains(t, err, tt.ExpectedError.Error())
+			} else {
+				// On Success
+				//
+				assert.NoError(t, err)
+				assert.Equal(t, "backend", res.Spec.Metadata["name"])
+				assert.Equal(t, tt.ExpectedContainer, res.Spec.Containers["main"])
+				assert.Equal(t, tt.ExpectedExtensions, res.Extensions)
+			}
+		})
+	}
+}
+
+func TestLoadWorkload_readFile(t *testing.T) {
+	const spec = `apiVersion: score.dev/v1b1
+metadata:
+  name: backend
+containers:
+  main:
+    image: busybox
+    files:
+      - target: /etc/backend/config.yaml
+        source: config.yaml
+`
+	var files = map[string]string{"workloads/backend/config.yaml": "name: ${metadata.name}"}
+
+	workload, err := LoadWorkload(LoadOptions{
+		Spec:    []byte(spec),
+		BaseDir: "workloads/backend",
+		ReadFile: func(path string) ([]byte, error) {
+			if content, ok := files[path]; ok {
+				return []byte(content), nil
+			}
+			return nil, errors.New("file not found")
+		},
+	})
+	assert.NoError(t, err)
+
+	delta, _, err := ConvertSpecs("test", "development", "", []WorkloadSource{*workload}, true)
+	assert.NoError(t, err)
+	var containers = delta.Modules.Add["backend"]["spec"].(map[string]interface{})["containers"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"/etc/backend/config.yaml": map[string]interface{}{"mode": "", "value": "name: backend"},
+	}, containers["main"].(map[string]interface{})["files"])
+
+	workload.BaseDir = "unknown"
+	_, _, err = ConvertSpecs("test", "development", "", []WorkloadSource{*workload}, true)
+	assert.ErrorContains(t, err, "reading 'unknown/config.yaml': file not found")
+}
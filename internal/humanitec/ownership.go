@@ -0,0 +1,81 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+// OwnershipConflict describes a deployed module the deployment delta would take over.
+type OwnershipConflict struct {
+	Module string
+	Reason string
+}
+
+// OwnershipError is returned by CheckOwnership when the deployment delta replaces modules owned by someone else.
+type OwnershipError struct {
+	Conflicts []OwnershipConflict
+}
+
+func (e *OwnershipError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("ownership check failed:")
+	for _, c := range e.Conflicts {
+		sb.WriteString(fmt.Sprintf("\n  - module '%s': %s", c.Module, c.Reason))
+	}
+	return sb.String()
+}
+
+// CheckOwnership checks that the modules added by the deployment delta only replace deployed modules managed by
+// score-humanitec. When the deployed module has a workload source URL, the generated module must have the same one.
+// Returns an OwnershipError listing the conflicting modules.
+func CheckOwnership(delta *humanitec.CreateDeploymentDeltaRequest, set *humanitec.Set) error {
+	var names = make([]string, 0, len(delta.Modules.Add))
+	for name := range delta.Modules.Add {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var conflicts []OwnershipConflict
+	for _, name := range names {
+		deployed, exists := set.Modules[name]
+		if !exists {
+			continue
+		}
+		var deployedAnnotations = moduleAnnotations(deployed)
+		var annotations = moduleAnnotations(delta.Modules.Add[name])
+
+		if owner, _ := deployedAnnotations[managedByAnnotation].(string); owner == "" {
+			conflicts = append(conflicts, OwnershipConflict{Module: name, Reason: fmt.Sprintf("not managed by %s", managedBy)})
+		} else if owner != managedBy {
+			conflicts = append(conflicts, OwnershipConflict{Module: name, Reason: fmt.Sprintf("managed by '%s'", owner)})
+		} else {
+			deployedSource, _ := deployedAnnotations[workloadSourceAnnotation].(string)
+			source, _ := annotations[workloadSourceAnnotation].(string)
+			if deployedSource != "" && deployedSource != source {
+				conflicts = append(conflicts, OwnershipConflict{Module: name, Reason: fmt.Sprintf("managed from '%s'", deployedSource)})
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return &OwnershipError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// moduleAnnotations returns the module workload annotations, or nil.
+func moduleAnnotations(module map[string]interface{}) map[string]interface{} {
+	var spec, _ = module["spec"].(map[string]interface{})
+	var annotations, _ = spec["annotations"].(map[string]interface{})
+	return annotations
+}
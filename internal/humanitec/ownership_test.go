@@ -0,0 +1,84 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+func TestCheckOwnership(t *testing.T) {
+	var module = func(annotations map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"profile": "humanitec/default-module",
+			"spec":    map[string]interface{}{"annotations": annotations},
+		}
+	}
+	var set = &humanitec.Set{
+		Modules: map[string]map[string]interface{}{
+			"score":       module(map[string]interface{}{managedByAnnotation: managedBy}),
+			"score-repo":  module(map[string]interface{}{managedByAnnotation: managedBy, workloadSourceAnnotation: "https://git.example.com/a"}),
+			"handcrafted": {"profile": "humanitec/default-module"},
+			"terraform":   module(map[string]interface{}{managedByAnnotation: "terraform"}),
+		},
+	}
+
+	var tests = []struct {
+		Name    string
+		Modules map[string]map[string]interface{}
+		Error   string
+	}{
+		{
+			Name: "Should accept new modules and modules managed by score-humanitec",
+			Modules: map[string]map[string]interface{}{
+				"new":        module(map[string]interface{}{managedByAnnotation: managedBy}),
+				"score":      module(map[string]interface{}{managedByAnnotation: managedBy, workloadSourceAnnotation: "https://git.example.com/b"}),
+				"score-repo": module(map[string]interface{}{managedByAnnotation: managedBy, workloadSourceAnnotation: "https://git.example.com/a"}),
+			},
+		},
+		{
+			Name: "Should reject modules owned by someone else",
+			Modules: map[string]map[string]interface{}{
+				"handcrafted": module(map[string]interface{}{managedByAnnotation: managedBy}),
+				"terraform":   module(map[string]interface{}{managedByAnnotation: managedBy}),
+				"score-repo":  module(map[string]interface{}{managedByAnnotation: managedBy, workloadSourceAnnotation: "https://git.example.com/b"}),
+			},
+			Error: "ownership check failed:" +
+				"\n  - module 'handcrafted': not managed by score-humanitec" +
+				"\n  - module 'score-repo': managed from 'https://git.example.com/a'" +
+				"\n  - module 'terraform': managed by 'terraform'",
+		},
+		{
+			Name: "Should reject unknown workload sources for modules managed from a known source",
+			Modules: map[string]map[string]interface{}{
+				"score-repo": module(map[string]interface{}{managedByAnnotation: managedBy}),
+			},
+			Error: "ownership check failed:" +
+				"\n  - module 'score-repo': managed from 'https://git.example.com/a'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var delta = &humanitec.CreateDeploymentDeltaRequest{
+				Modules: humanitec.ModuleDeltas{Add: tt.Modules},
+			}
+			err := CheckOwnership(delta, set)
+			if tt.Error == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.Error)
+				var ownershipErr *OwnershipError
+				assert.ErrorAs(t, err, &ownershipErr)
+			}
+		})
+	}
+}
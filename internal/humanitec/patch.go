@@ -0,0 +1,170 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+// ApplyDelta applies the deployment delta to a copy of the deployment set, the same way Humanitec does on deploy.
+func ApplyDelta(set *humanitec.Set, delta *humanitec.CreateDeploymentDeltaRequest) (*humanitec.Set, error) {
+	var res humanitec.Set
+	if err := normalize(set, &res); err != nil {
+		return nil, fmt.Errorf("copying deployment set: %w", err)
+	}
+	var changes humanitec.CreateDeploymentDeltaRequest
+	if err := normalize(delta, &changes); err != nil {
+		return nil, fmt.Errorf("copying deployment delta: %w", err)
+	}
+	if res.Modules == nil {
+		res.Modules = make(map[string]map[string]interface{})
+	}
+	if res.Shared == nil {
+		res.Shared = make(map[string]interface{})
+	}
+
+	for name, mod := range changes.Modules.Add {
+		res.Modules[name] = mod
+	}
+	for _, name := range changes.Modules.Remove {
+		delete(res.Modules, name)
+	}
+	for name, actions := range changes.Modules.Update {
+		mod, exists := res.Modules[name]
+		if !exists {
+			return nil, fmt.Errorf("updating module '%s': module does not exist", name)
+		}
+		for _, action := range actions {
+			if err := applyAction(mod, action); err != nil {
+				return nil, fmt.Errorf("updating module '%s': %w", name, err)
+			}
+		}
+	}
+	for _, action := range changes.Shared {
+		if err := applyAction(res.Shared, action); err != nil {
+			return nil, fmt.Errorf("updating shared resources: %w", err)
+		}
+	}
+
+	return &res, nil
+}
+
+// normalize copies the source into the destination through JSON, so that values have the same types as API responses.
+func normalize(src, dst interface{}) error {
+	raw, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, dst)
+}
+
+// splitPointer splits a JSON pointer (RFC 6901) into unescaped reference tokens.
+func splitPointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("invalid path '%s': must start with '/'", path)
+	}
+	var tokens = strings.Split(path[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// joinPointer builds a JSON pointer (RFC 6901) from the reference tokens.
+func joinPointer(tokens ...string) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteString("/")
+		sb.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return sb.String()
+}
+
+// applyAction applies a single JSON Patch (RFC 6902) operation to the document.
+// Only 'add', 'remove' and 'replace' operations are supported.
+func applyAction(doc map[string]interface{}, action humanitec.UpdateAction) error {
+	tokens, err := splitPointer(action.Path)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("%s '%s': can not change the document root", action.Operation, action.Path)
+	}
+
+	// Find the parent container
+	var parent interface{} = doc
+	var setParent = func(interface{}) {}
+	for _, token := range tokens[:len(tokens)-1] {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			var key = token
+			parent = p[key]
+			setParent = func(v interface{}) { p[key] = v }
+		case []interface{}:
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= len(p) {
+				return fmt.Errorf("%s '%s': path not found", action.Operation, action.Path)
+			}
+			parent = p[idx]
+			setParent = func(v interface{}) { p[idx] = v }
+		default:
+			return fmt.Errorf("%s '%s': path not found", action.Operation, action.Path)
+		}
+	}
+
+	var last = tokens[len(tokens)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		switch action.Operation {
+		case "add":
+			p[last] = action.Value
+		case "replace":
+			if _, exists := p[last]; !exists {
+				return fmt.Errorf("%s '%s': path not found", action.Operation, action.Path)
+			}
+			p[last] = action.Value
+		case "remove":
+			if _, exists := p[last]; !exists {
+				return fmt.Errorf("%s '%s': path not found", action.Operation, action.Path)
+			}
+			delete(p, last)
+		default:
+			return fmt.Errorf("%s '%s': operation not supported", action.Operation, action.Path)
+		}
+	case []interface{}:
+		var idx = len(p)
+		if last != "-" || action.Operation != "add" {
+			if idx, err = strconv.Atoi(last); err != nil || idx < 0 || idx > len(p) || (idx == len(p) && action.Operation != "add") {
+				return fmt.Errorf("%s '%s': path not found", action.Operation, action.Path)
+			}
+		}
+		switch action.Operation {
+		case "add":
+			p = append(p[:idx], append([]interface{}{action.Value}, p[idx:]...)...)
+		case "replace":
+			p[idx] = action.Value
+		case "remove":
+			p = append(p[:idx], p[idx+1:]...)
+		default:
+			return fmt.Errorf("%s '%s': operation not supported", action.Operation, action.Path)
+		}
+		setParent(p)
+	default:
+		return fmt.Errorf("%s '%s': path not found", action.Operation, action.Path)
+	}
+
+	return nil
+}
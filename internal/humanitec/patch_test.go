@@ -0,0 +1,136 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+func TestApplyDelta(t *testing.T) {
+	var set = &humanitec.Set{
+		Modules: map[string]map[string]interface{}{
+			"backend": {
+				"profile": "humanitec/default-module",
+				"spec": map[string]interface{}{
+					"replicas": 1,
+					"args":     []string{"-c", "run"},
+				},
+			},
+			"legacy": {
+				"profile": "humanitec/default-module",
+			},
+		},
+		Shared: map[string]interface{}{
+			"dns": map[string]interface{}{"type": "dns"},
+		},
+	}
+
+	var tests = []struct {
+		Name   string
+		Delta  *humanitec.CreateDeploymentDeltaRequest
+		Output *humanitec.Set
+		Error  error
+	}{
+		{
+			Name: "Should apply all delta changes",
+			Delta: &humanitec.CreateDeploymentDeltaRequest{
+				Modules: humanitec.ModuleDeltas{
+					Add: map[string]map[string]interface{}{
+						"frontend": {"profile": "humanitec/default-module"},
+					},
+					Remove: []string{"legacy"},
+					Update: map[string][]humanitec.UpdateAction{
+						"backend": {
+							{Operation: "replace", Path: "/spec/replicas", Value: 2},
+							{Operation: "add", Path: "/spec/args/-", Value: "--debug"},
+							{Operation: "add", Path: "/spec/labels", Value: map[string]interface{}{"a/b": "c"}},
+							{Operation: "remove", Path: "/spec/labels/a~1b"},
+						},
+					},
+				},
+				Shared: []humanitec.UpdateAction{
+					{Operation: "remove", Path: "/dns"},
+					{Operation: "add", Path: "/bucket", Value: map[string]interface{}{"type": "s3"}},
+				},
+			},
+			Output: &humanitec.Set{
+				Modules: map[string]map[string]interface{}{
+					"backend": {
+						"profile": "humanitec/default-module",
+						"spec": map[string]interface{}{
+							"replicas": float64(2),
+							"args":     []interface{}{"-c", "run", "--debug"},
+							"labels":   map[string]interface{}{},
+						},
+					},
+					"frontend": {
+						"profile": "humanitec/default-module",
+					},
+				},
+				Shared: map[string]interface{}{
+					"bucket": map[string]interface{}{"type": "s3"},
+				},
+			},
+		},
+		{
+			Name: "Should reject updates to missing modules",
+			Delta: &humanitec.CreateDeploymentDeltaRequest{
+				Modules: humanitec.ModuleDeltas{
+					Update: map[string][]humanitec.UpdateAction{
+						"nil": {{Operation: "remove", Path: "/spec"}},
+					},
+				},
+			},
+			Error: errors.New("updating module 'nil': module does not exist"),
+		},
+		{
+			Name: "Should reject missing paths",
+			Delta: &humanitec.CreateDeploymentDeltaRequest{
+				Shared: []humanitec.UpdateAction{
+					{Operation: "replace", Path: "/nil/type", Value: "dns"},
+				},
+			},
+			Error: errors.New("replace '/nil/type': path not found"),
+		},
+		{
+			Name: "Should reject unsupported operations",
+			Delta: &humanitec.CreateDeploymentDeltaRequest{
+				Shared: []humanitec.UpdateAction{
+					{Operation: "move", Path: "/dns", From: "/dns2"},
+				},
+			},
+			Error: errors.New("operation not supported"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			res, err := ApplyDelta(set, tt.Delta)
+
+			if tt.Error != nil {
+				// On Error
+				//
+				assert.ErrorContains(t, err, tt.Error.Error())
+			} else {
+				// On Success
+				//
+				assert.NoError(t, err)
+				assert.Equal(t, tt.Output, res)
+			}
+		})
+	}
+
+	// The source set should not be modified
+	assert.Contains(t, set.Modules, "legacy")
+	assert.Contains(t, set.Shared, "dns")
+}
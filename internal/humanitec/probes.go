@@ -0,0 +1,122 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	score "github.com/score-spec/score-go/types"
+)
+
+// ContainerProbe is a container probe specification.
+// It extends the SCORE probe with exec and tcpSocket handlers, and with probe timings.
+type ContainerProbe struct {
+	HttpGet   *score.HttpProbe `mapstructure:"httpGet"`
+	Exec      *ExecProbe       `mapstructure:"exec"`
+	TcpSocket *TcpSocketProbe  `mapstructure:"tcpSocket"`
+
+	InitialDelaySeconds *int `mapstructure:"initialDelaySeconds"`
+	PeriodSeconds       *int `mapstructure:"periodSeconds"`
+	TimeoutSeconds      *int `mapstructure:"timeoutSeconds"`
+	SuccessThreshold    *int `mapstructure:"successThreshold"`
+	FailureThreshold    *int `mapstructure:"failureThreshold"`
+}
+
+// ExecProbe is a probe that runs a command inside the container.
+type ExecProbe struct {
+	Command []string `mapstructure:"command"`
+}
+
+// TcpSocketProbe is a probe that opens a TCP connection to the container.
+type TcpSocketProbe struct {
+	Host *string `mapstructure:"host"`
+	Port int     `mapstructure:"port"`
+}
+
+// ContainerProbes holds the container liveness and readiness probes.
+type ContainerProbes struct {
+	Liveness  *ContainerProbe
+	Readiness *ContainerProbe
+}
+
+// ExtractProbes removes the probes that can not be expressed with SCORE types (exec and tcpSocket handlers,
+// and probe timings) from the source spec, and returns them by container name.
+// Plain httpGet probes are left in the source spec. Unknown probe fields are reported as errors.
+func ExtractProbes(srcMap map[string]interface{}) (map[string]ContainerProbes, error) {
+	var res = make(map[string]ContainerProbes)
+
+	containers, _ := srcMap["containers"].(map[string]interface{})
+	for cName, cVal := range containers {
+		container, ok := cVal.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		var probes ContainerProbes
+		for _, field := range []struct {
+			key    string
+			target **ContainerProbe
+		}{
+			{"livenessProbe", &probes.Liveness},
+			{"readinessProbe", &probes.Readiness},
+		} {
+			probeMap, ok := container[field.key].(map[string]interface{})
+			if !ok || isScoreProbe(probeMap) {
+				continue
+			}
+
+			var probe ContainerProbe
+			var metadata mapstructure.Metadata
+			decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+				Metadata: &metadata,
+				Result:   &probe,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := decoder.Decode(probeMap); err != nil {
+				return nil, fmt.Errorf("containers.%s.%s: %w", cName, field.key, err)
+			}
+			// Misspelled fields would be silently ignored otherwise
+			if len(metadata.Unused) > 0 {
+				sort.Strings(metadata.Unused)
+				return nil, fmt.Errorf("containers.%s.%s: unknown field '%s'", cName, field.key, strings.Join(metadata.Unused, "', '"))
+			}
+			*field.target = &probe
+			delete(container, field.key)
+		}
+
+		if probes.Liveness != nil || probes.Readiness != nil {
+			res[cName] = probes
+		}
+	}
+
+	return res, nil
+}
+
+// isScoreProbe returns true if the probe only uses the fields supported by the SCORE schema.
+func isScoreProbe(probe map[string]interface{}) bool {
+	for key := range probe {
+		if key != "httpGet" {
+			return false
+		}
+	}
+	return true
+}
+
+// fromScoreProbe converts the SCORE probe into the extended probe specification.
+func fromScoreProbe(probe *score.ContainerProbe) *ContainerProbe {
+	if probe == nil {
+		return nil
+	}
+	var httpGet = probe.HttpGet
+	return &ContainerProbe{HttpGet: &httpGet}
+}
@@ -0,0 +1,102 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"testing"
+
+	score "github.com/score-spec/score-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractProbes(t *testing.T) {
+	var srcMap = map[string]interface{}{
+		"containers": map[string]interface{}{
+			"backend": map[string]interface{}{
+				"image": "busybox",
+				"livenessProbe": map[string]interface{}{
+					"exec": map[string]interface{}{
+						"command": []interface{}{"/bin/sh", "-c", "true"},
+					},
+					"periodSeconds": 10,
+				},
+				"readinessProbe": map[string]interface{}{
+					"httpGet": map[string]interface{}{
+						"path": "/health",
+						"port": 8080,
+					},
+				},
+			},
+			"db": map[string]interface{}{
+				"image": "postgres",
+				"readinessProbe": map[string]interface{}{
+					"tcpSocket": map[string]interface{}{
+						"port": float64(5432),
+					},
+				},
+			},
+			"sidecar": map[string]interface{}{
+				"image": "busybox",
+			},
+		},
+	}
+
+	probes, err := ExtractProbes(srcMap)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]ContainerProbes{
+		"backend": {
+			Liveness: &ContainerProbe{
+				Exec:          &ExecProbe{Command: []string{"/bin/sh", "-c", "true"}},
+				PeriodSeconds: Ref(10),
+			},
+		},
+		"db": {
+			Readiness: &ContainerProbe{
+				TcpSocket: &TcpSocketProbe{Port: 5432},
+			},
+		},
+	}, probes)
+
+	// Extended probes are removed, SCORE probes are kept
+	var containers = srcMap["containers"].(map[string]interface{})
+	assert.NotContains(t, containers["backend"], "livenessProbe")
+	assert.Contains(t, containers["backend"], "readinessProbe")
+	assert.NotContains(t, containers["db"], "readinessProbe")
+
+	// Invalid probes are reported
+	_, err = ExtractProbes(map[string]interface{}{
+		"containers": map[string]interface{}{
+			"backend": map[string]interface{}{
+				"livenessProbe": map[string]interface{}{
+					"exec": "true",
+				},
+			},
+		},
+	})
+	assert.ErrorContains(t, err, "containers.backend.livenessProbe")
+
+	// Unknown fields are reported
+	_, err = ExtractProbes(map[string]interface{}{
+		"containers": map[string]interface{}{
+			"backend": map[string]interface{}{
+				"livenessProbe": map[string]interface{}{
+					"tcpSocket":          map[string]interface{}{"port": 80, "hostname": "localhost"},
+					"initialDelaySecond": 5,
+				},
+			},
+		},
+	})
+	assert.EqualError(t, err, "containers.backend.livenessProbe: unknown field 'initialDelaySecond', 'tcpSocket.hostname'")
+}
+
+func TestFromScoreProbe(t *testing.T) {
+	assert.Nil(t, fromScoreProbe(nil))
+	assert.Equal(t,
+		&ContainerProbe{HttpGet: &score.HttpProbe{Path: "/health", Port: 8080}},
+		fromScoreProbe(&score.ContainerProbe{HttpGet: score.HttpProbe{Path: "/health", Port: 8080}}))
+}
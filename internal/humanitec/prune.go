@@ -0,0 +1,170 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	extensions "github.com/score-spec/score-humanitec/internal/humanitec/extensions"
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+// PruneSummary lists the modules and shared resources removed by PruneDelta.
+type PruneSummary struct {
+	Modules []string `json:"modules,omitempty"`
+	Shared  []string `json:"shared,omitempty"`
+
+	// Warnings explain why some resources are kept.
+	Warnings []Warning `json:"-"`
+}
+
+// IsEmpty returns true if nothing is pruned.
+func (s *PruneSummary) IsEmpty() bool {
+	return len(s.Modules) == 0 && len(s.Shared) == 0
+}
+
+// AnnotateSharedResources records the shared resources declared by each workload in the annotations of its module
+// added by the delta, for PruneDelta to remove them once no workload declares them anymore.
+// Shared resources declared by modules deployed without this record are never pruned.
+func AnnotateSharedResources(delta *humanitec.CreateDeploymentDeltaRequest, workloads []WorkloadSource) {
+	for _, w := range workloads {
+		var wName, _ = w.Spec.Metadata["name"].(string)
+		var module, exists = delta.Modules.Add[wName]
+		if !exists {
+			continue
+		}
+		if names := sharedResourceNames(w); len(names) > 0 {
+			setModuleAnnotation(module, sharedResourcesAnnotation, encodeList(names))
+		}
+	}
+}
+
+// sharedResourceNames returns the sorted names of the shared resources declared by the workload,
+// as they are named in the deployment set.
+func sharedResourceNames(source WorkloadSource) []string {
+	var wName, _ = source.Spec.Metadata["name"].(string)
+	var deprecated extensions.HumanitecResourcesSpecs
+	if source.Extensions != nil {
+		deprecated = source.Extensions.Resources
+	}
+
+	var names []string
+	for name, res := range source.Spec.Resources {
+		switch res.Type {
+		case "service", "environment", "workload":
+			continue
+		}
+		mod, scope, resName, err := parseResourceId(resourceRef(name, res, deprecated))
+		if err != nil || scope != "shared" || (mod != "" && mod != wName) {
+			continue
+		}
+		if class := DerefOr(res.Class, "default"); class != "default" {
+			resName = resName + "-class-" + class
+		}
+		names = append(names, resName)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PruneDelta adds the removals of the modules and shared resources deployed in the set by score-humanitec, and not
+// declared by the delta anymore. Modules are pruned if they are annotated as managed by score-humanitec, and shared
+// resources if they were declared by such a module. Shared resources still referenced by a module that is not managed
+// by score-humanitec are kept.
+// The workloads are the names of all the converted workloads, as the modules that are up to date are not part of the
+// delta once UpdateModules is applied.
+func PruneDelta(delta *humanitec.CreateDeploymentDeltaRequest, set *humanitec.Set, workloads []string) (*humanitec.CreateDeploymentDeltaRequest, *PruneSummary, error) {
+	var res humanitec.CreateDeploymentDeltaRequest
+	if err := normalize(delta, &res); err != nil {
+		return nil, nil, fmt.Errorf("copying deployment delta: %w", err)
+	}
+
+	var declared = make(map[string]bool)
+	for _, name := range workloads {
+		declared[name] = true
+	}
+	for name := range res.Modules.Add {
+		declared[name] = true
+	}
+	for name := range res.Modules.Update {
+		declared[name] = true
+	}
+	var declaredShared = make(map[string]bool)
+	for _, action := range res.Shared {
+		if action.Operation == "add" {
+			declaredShared[strings.TrimPrefix(action.Path, "/")] = true
+		}
+	}
+
+	var names = make([]string, 0, len(set.Modules))
+	for name := range set.Modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var summary PruneSummary
+	var candidates = make(map[string]bool)
+	var unmanaged []string
+	for _, name := range names {
+		var annotations = moduleAnnotations(set.Modules[name])
+		if annotations[managedByAnnotation] != managedBy {
+			unmanaged = append(unmanaged, name)
+			continue
+		}
+		for _, resName := range decodeList(annotations[sharedResourcesAnnotation]) {
+			candidates[resName] = true
+		}
+		if !declared[name] {
+			summary.Modules = append(summary.Modules, name)
+		}
+	}
+
+	var sharedNames = make([]string, 0, len(candidates))
+	for resName := range candidates {
+		if _, deployed := set.Shared[resName]; deployed && !declaredShared[resName] {
+			sharedNames = append(sharedNames, resName)
+		}
+	}
+	sort.Strings(sharedNames)
+	for _, resName := range sharedNames {
+		if user := findSharedReference(set, unmanaged, resName); user != "" {
+			summary.Warnings = append(summary.Warnings, Warning{
+				Message: fmt.Sprintf("shared resource '%s' is still used by module '%s'. It will not be pruned.", resName, user),
+			})
+			continue
+		}
+		summary.Shared = append(summary.Shared, resName)
+	}
+
+	res.Modules.Remove = append(res.Modules.Remove, summary.Modules...)
+	for _, resName := range summary.Shared {
+		res.Shared = append(res.Shared, humanitec.UpdateAction{
+			Operation: "remove",
+			Path:      "/" + resName,
+		})
+	}
+
+	return &res, &summary, nil
+}
+
+// findSharedReference returns the name of the first module referencing the shared resource, or an empty string.
+func findSharedReference(set *humanitec.Set, modules []string, resName string) string {
+	var ref = fmt.Sprintf("${shared.%s", resName)
+	for _, name := range modules {
+		raw, _ := json.Marshal(set.Modules[name])
+		for _, suffix := range []string{".", "}"} {
+			if strings.Contains(string(raw), ref+suffix) {
+				return name
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,213 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"testing"
+
+	score "github.com/score-spec/score-go/types"
+	"github.com/stretchr/testify/assert"
+
+	extensions "github.com/score-spec/score-humanitec/internal/humanitec/extensions"
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+func TestPruneDelta(t *testing.T) {
+	var scoreModule = func(shared string) map[string]interface{} {
+		var annotations = map[string]interface{}{managedByAnnotation: managedBy}
+		if shared != "" {
+			annotations[sharedResourcesAnnotation] = shared
+		}
+		return map[string]interface{}{
+			"profile": "humanitec/default-module",
+			"spec":    map[string]interface{}{"annotations": annotations},
+		}
+	}
+	var set = &humanitec.Set{
+		Modules: map[string]map[string]interface{}{
+			"backend":  scoreModule(`["dns","queue"]`),
+			"frontend": scoreModule(`["dns","bucket-class-large"]`),
+			"worker":   scoreModule(""),
+			"legacy": {
+				"profile": "humanitec/default-module",
+				"spec": map[string]interface{}{
+					"containers": map[string]interface{}{
+						"main": map[string]interface{}{
+							"variables": map[string]interface{}{"QUEUE": "${shared.queue.name}"},
+						},
+					},
+				},
+			},
+		},
+		Shared: map[string]interface{}{
+			"dns":                map[string]interface{}{"type": "dns"},
+			"queue":              map[string]interface{}{"type": "amqp"},
+			"bucket-class-large": map[string]interface{}{"type": "s3", "class": "large"},
+			"database":           map[string]interface{}{"type": "postgres"},
+		},
+	}
+
+	var tests = []struct {
+		Name      string
+		Workloads []string
+		Delta     *humanitec.CreateDeploymentDeltaRequest
+		Output    *humanitec.CreateDeploymentDeltaRequest
+		Summary   *PruneSummary
+	}{
+		{
+			Name:      "Should prune removed workloads and shared resources",
+			Workloads: []string{"backend"},
+			Delta: &humanitec.CreateDeploymentDeltaRequest{
+				Metadata: humanitec.DeltaMetadata{EnvID: "development", Name: "test"},
+				Modules: humanitec.ModuleDeltas{
+					Add: map[string]map[string]interface{}{"backend": scoreModule(`["dns","queue"]`)},
+				},
+				Shared: []humanitec.UpdateAction{
+					{Operation: "add", Path: "/dns", Value: map[string]interface{}{"type": "dns"}},
+				},
+			},
+			Output: &humanitec.CreateDeploymentDeltaRequest{
+				Metadata: humanitec.DeltaMetadata{EnvID: "development", Name: "test"},
+				Modules: humanitec.ModuleDeltas{
+					Add:    map[string]map[string]interface{}{"backend": scoreModule(`["dns","queue"]`)},
+					Remove: []string{"frontend", "worker"},
+				},
+				Shared: []humanitec.UpdateAction{
+					{Operation: "add", Path: "/dns", Value: map[string]interface{}{"type": "dns"}},
+					{Operation: "remove", Path: "/bucket-class-large"},
+				},
+			},
+			Summary: &PruneSummary{
+				Modules:  []string{"frontend", "worker"},
+				Shared:   []string{"bucket-class-large"},
+				Warnings: []Warning{{Message: "shared resource 'queue' is still used by module 'legacy'. It will not be pruned."}},
+			},
+		},
+		{
+			Name:      "Should keep updated workloads",
+			Workloads: []string{"backend", "frontend", "worker"},
+			Delta: &humanitec.CreateDeploymentDeltaRequest{
+				Modules: humanitec.ModuleDeltas{
+					Add: map[string]map[string]interface{}{"backend": scoreModule("")},
+					Update: map[string][]humanitec.UpdateAction{
+						"frontend": {{Operation: "replace", Path: "/profile", Value: "humanitec/worker"}},
+						"worker":   {{Operation: "replace", Path: "/profile", Value: "humanitec/worker"}},
+					},
+				},
+				Shared: []humanitec.UpdateAction{
+					{Operation: "add", Path: "/dns", Value: map[string]interface{}{"type": "dns"}},
+					{Operation: "add", Path: "/bucket-class-large", Value: map[string]interface{}{"type": "s3", "class": "large"}},
+				},
+			},
+			Output: &humanitec.CreateDeploymentDeltaRequest{
+				Modules: humanitec.ModuleDeltas{
+					Add: map[string]map[string]interface{}{"backend": scoreModule("")},
+					Update: map[string][]humanitec.UpdateAction{
+						"frontend": {{Operation: "replace", Path: "/profile", Value: "humanitec/worker"}},
+						"worker":   {{Operation: "replace", Path: "/profile", Value: "humanitec/worker"}},
+					},
+				},
+				Shared: []humanitec.UpdateAction{
+					{Operation: "add", Path: "/dns", Value: map[string]interface{}{"type": "dns"}},
+					{Operation: "add", Path: "/bucket-class-large", Value: map[string]interface{}{"type": "s3", "class": "large"}},
+				},
+			},
+			Summary: &PruneSummary{
+				Warnings: []Warning{{Message: "shared resource 'queue' is still used by module 'legacy'. It will not be pruned."}},
+			},
+		},
+		{
+			Name:      "Should keep up to date workloads",
+			Workloads: []string{"backend", "frontend", "worker"},
+			Delta: &humanitec.CreateDeploymentDeltaRequest{
+				Shared: []humanitec.UpdateAction{
+					{Operation: "add", Path: "/dns", Value: map[string]interface{}{"type": "dns"}},
+					{Operation: "add", Path: "/bucket-class-large", Value: map[string]interface{}{"type": "s3", "class": "large"}},
+				},
+			},
+			Output: &humanitec.CreateDeploymentDeltaRequest{
+				Shared: []humanitec.UpdateAction{
+					{Operation: "add", Path: "/dns", Value: map[string]interface{}{"type": "dns"}},
+					{Operation: "add", Path: "/bucket-class-large", Value: map[string]interface{}{"type": "s3", "class": "large"}},
+				},
+			},
+			Summary: &PruneSummary{
+				Warnings: []Warning{{Message: "shared resource 'queue' is still used by module 'legacy'. It will not be pruned."}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			res, summary, err := PruneDelta(tt.Delta, set, tt.Workloads)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.Output, res)
+			assert.Equal(t, tt.Summary, summary)
+
+			// Removals must apply to the deployed set
+			after, err := ApplyDelta(set, res)
+			assert.NoError(t, err)
+			for _, name := range summary.Modules {
+				assert.NotContains(t, after.Modules, name)
+			}
+			for _, resName := range summary.Shared {
+				assert.NotContains(t, after.Shared, resName)
+			}
+		})
+	}
+}
+
+func TestAnnotateSharedResources(t *testing.T) {
+	var large = "large"
+	var workloads = []WorkloadSource{
+		{
+			Spec: &score.Workload{
+				Metadata: score.WorkloadMetadata{"name": "backend"},
+				Resources: map[string]score.Resource{
+					"db": {Type: "postgres"},
+					"dns": {Type: "dns", Metadata: score.ResourceMetadata{
+						"annotations": map[string]interface{}{AnnotationLabelResourceId: "shared.dns"},
+					}},
+					"bucket": {Type: "s3", Class: &large, Metadata: score.ResourceMetadata{
+						"annotations": map[string]interface{}{AnnotationLabelResourceId: "shared.bucket"},
+					}},
+					"queue": {Type: "amqp"},
+					"other": {Type: "dns", Metadata: score.ResourceMetadata{
+						"annotations": map[string]interface{}{AnnotationLabelResourceId: "modules.frontend.shared.dns"},
+					}},
+				},
+			},
+			Extensions: &extensions.HumanitecExtensionsSpec{
+				Resources: extensions.HumanitecResourcesSpecs{"queue": {Scope: "shared"}},
+			},
+		},
+		{
+			Spec: &score.Workload{
+				Metadata:  score.WorkloadMetadata{"name": "frontend"},
+				Resources: map[string]score.Resource{"db": {Type: "postgres"}},
+			},
+		},
+	}
+	var delta = &humanitec.CreateDeploymentDeltaRequest{
+		Modules: humanitec.ModuleDeltas{
+			Add: map[string]map[string]interface{}{
+				"backend":  {"spec": map[string]interface{}{"annotations": map[string]interface{}{managedByAnnotation: managedBy}}},
+				"frontend": {"spec": map[string]interface{}{"annotations": map[string]interface{}{managedByAnnotation: managedBy}}},
+			},
+		},
+	}
+
+	AnnotateSharedResources(delta, workloads)
+	assert.Equal(t, map[string]interface{}{
+		managedByAnnotation:       managedBy,
+		sharedResourcesAnnotation: `["bucket-class-large","dns","queue"]`,
+	}, moduleAnnotations(delta.Modules.Add["backend"]))
+	assert.Equal(t, map[string]interface{}{
+		managedByAnnotation: managedBy,
+	}, moduleAnnotations(delta.Modules.Add["frontend"]))
+}
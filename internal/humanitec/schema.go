@@ -0,0 +1,71 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+var (
+	//go:embed schemas/*.json
+	schemasFS embed.FS
+
+	deltaSchema     *jsonschema.Schema
+	deltaSchemaErr  error
+	deltaSchemaOnce sync.Once
+)
+
+// compileDeltaSchema compiles the embedded deployment delta schema, along with the workload profiles schemas it references.
+func compileDeltaSchema() (*jsonschema.Schema, error) {
+	deltaSchemaOnce.Do(func() {
+		var compiler = jsonschema.NewCompiler()
+		deltaSchemaErr = fs.WalkDir(schemasFS, "schemas", func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			src, err := schemasFS.Open(path)
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+			return compiler.AddResource(strings.TrimPrefix(path, "schemas/"), src)
+		})
+		if deltaSchemaErr == nil {
+			deltaSchema, deltaSchemaErr = compiler.Compile("delta.schema.json")
+		}
+	})
+	return deltaSchema, deltaSchemaErr
+}
+
+// ValidateDelta checks the deployment delta against the embedded JSON schema.
+// Workload specs using the default workload profile are checked against the profile schema.
+// All violations are reported together as a ValidationError with JSON pointer locations.
+func ValidateDelta(delta *humanitec.CreateDeploymentDeltaRequest) error {
+	schema, err := compileDeltaSchema()
+	if err != nil {
+		return fmt.Errorf("compiling deployment delta schema: %w", err)
+	}
+
+	var doc interface{}
+	if err := normalize(delta, &doc); err != nil {
+		return fmt.Errorf("marshalling deployment delta: %w", err)
+	}
+
+	if problems := schemaViolations(schema.Validate(doc)); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"testing"
+
+	score "github.com/score-spec/score-go/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/score-spec/score-humanitec/internal/humanitec/extensions"
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+func TestValidateDelta(t *testing.T) {
+	var tests = []struct {
+		Name  string
+		Delta *humanitec.CreateDeploymentDeltaRequest
+		Error string
+	}{
+		{
+			Name: "Should accept a valid deployment delta",
+			Delta: &humanitec.CreateDeploymentDeltaRequest{
+				Metadata: humanitec.DeltaMetadata{EnvID: "test", Name: "Test delta"},
+				Modules: humanitec.ModuleDeltas{
+					Add: map[string]map[string]interface{}{
+						"backend": {
+							"profile": "humanitec/default-module",
+							"spec": map[string]interface{}{
+								"containers": map[string]interface{}{
+									"backend": map[string]interface{}{
+										"id":        "backend",
+										"image":     "busybox",
+										"variables": map[string]interface{}{"DEBUG": "true"},
+										"liveness_probe": map[string]interface{}{
+											"type": "http",
+											"path": "/alive",
+											"port": 8080,
+										},
+									},
+								},
+								"schedules": map[string]interface{}{"anything": "goes"},
+							},
+							"externals": map[string]interface{}{
+								"db": map[string]interface{}{"type": "postgres", "class": "default"},
+							},
+						},
+						"custom": {
+							"profile": "test-org/custom-module",
+							"spec":    map[string]interface{}{"containers": "not validated"},
+						},
+					},
+				},
+				Shared: []humanitec.UpdateAction{
+					{Operation: "add", Path: "/dns", Value: map[string]interface{}{"type": "dns"}},
+				},
+			},
+		},
+		{
+			Name: "Should report all violations with JSON pointers",
+			Delta: &humanitec.CreateDeploymentDeltaRequest{
+				Modules: humanitec.ModuleDeltas{
+					Add: map[string]map[string]interface{}{
+						"backend": {
+							"profile": "humanitec/default-module",
+							"spec": map[string]interface{}{
+								"labels": map[string]interface{}{"replicas": 3},
+								"containers": map[string]interface{}{
+									"backend": map[string]interface{}{
+										"id":    "backend",
+										"image": 1,
+										"readiness_probe": map[string]interface{}{
+											"type": "tcp",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				Shared: []humanitec.UpdateAction{
+					{Operation: "insert", Path: "dns"},
+				},
+			},
+			Error: `validation failed:
+  - /modules/add/backend/spec/containers/backend/image: expected string, but got number
+  - /modules/add/backend/spec/containers/backend/readiness_probe: missing properties: 'port'
+  - /modules/add/backend/spec/labels/replicas: expected string, but got number
+  - /shared/0/op: value must be one of "add", "remove", "replace", "move", "copy", "test"
+  - /shared/0/path: does not match pattern '^/'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			err := ValidateDelta(tt.Delta)
+
+			if tt.Error != "" {
+				// On Error
+				//
+				assert.EqualError(t, err, tt.Error)
+			} else {
+				// On Success
+				//
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateDelta_converted(t *testing.T) {
+	var spec = &score.Workload{
+		Metadata: score.WorkloadMetadata{"name": "backend"},
+		Service: &score.WorkloadService{
+			Ports: score.WorkloadServicePorts{
+				"www": score.ServicePort{Port: 80, TargetPort: Ref(8080)},
+			},
+		},
+		Containers: score.WorkloadContainers{
+			"backend": score.Container{
+				Image: "busybox",
+				Files: []score.ContainerFilesElem{
+					{Target: "/etc/config", Mode: Ref("0644"), Content: Ref("DEBUG=1")},
+				},
+				Volumes: []score.ContainerVolumesElem{
+					{Source: "${resources.data}", Target: "/mnt/data"},
+				},
+			},
+		},
+		Resources: map[string]score.Resource{
+			"data": {Type: "volume"},
+		},
+	}
+
+	delta, err := ConvertSpec("Test delta", "test", "", "", spec, &extensions.HumanitecExtensionsSpec{})
+	assert.NoError(t, err)
+	assert.NoError(t, ValidateDelta(delta))
+
+	// Container fields not generated from SCORE are still supported by the profile
+	delta, err = ConvertSpec("Test delta", "test", "", "", spec, &extensions.HumanitecExtensionsSpec{
+		Spec: map[string]interface{}{
+			"containers": map[string]interface{}{
+				"backend": map[string]interface{}{
+					"startup_probe": map[string]interface{}{"type": "tcp", "port": 8080},
+					"working_dir":   "/app",
+					"stdin":         true,
+					"tty":           true,
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, ValidateDelta(delta))
+	assert.Contains(t, delta.Modules.Add["backend"]["spec"].(map[string]interface{})["containers"].(map[string]interface{})["backend"], "startup_probe")
+
+	delta, err = ConvertSpec("Test delta", "test", "", "", spec, &extensions.HumanitecExtensionsSpec{
+		Spec: map[string]interface{}{
+			"ingress": map[string]interface{}{
+				"rules": map[string]interface{}{
+					"example.com": map[string]interface{}{
+						"http": map[string]interface{}{
+							"/": map[string]interface{}{"type": "prefix", "port": "eighty"},
+						},
+					},
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.EqualError(t, ValidateDelta(delta), `validation failed:
+  - /modules/add/backend/spec/ingress/rules/example.com/http/~1/port: expected integer, but got string`)
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"log"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/mitchellh/mapstructure"
@@ -24,11 +25,59 @@ var (
 	placeholderRegEx = regexp.MustCompile(`\$(\$|{([a-zA-Z0-9.\-_\[\]"'#]+)})`)
 )
 
+// UnresolvedReference describes a '${...}' template that could not be resolved.
+type UnresolvedReference struct {
+	Workload string
+	Location string
+	Ref      string
+}
+
+// Warning is a non fatal problem found while converting a workload, e.g. an ignored or deprecated feature.
+type Warning struct {
+	Workload string
+	Message  string
+}
+
+func (w Warning) String() string {
+	if w.Workload == "" {
+		return w.Message
+	}
+	return fmt.Sprintf("workload '%s': %s", w.Workload, w.Message)
+}
+
+// UnresolvedReferencesError is returned in strict mode when some '${...}' templates could not be resolved.
+type UnresolvedReferencesError struct {
+	References []UnresolvedReference
+}
+
+func (e *UnresolvedReferencesError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("unresolved references:")
+	for _, ref := range e.References {
+		sb.WriteString(fmt.Sprintf("\n  - '${%s}' in %s", ref.Ref, ref.Location))
+		if ref.Workload != "" {
+			sb.WriteString(fmt.Sprintf(" (workload '%s')", ref.Workload))
+		}
+	}
+	return sb.String()
+}
+
 // templatesContext ia an utility type that provides a context for '${...}' templates substitution
 type templatesContext struct {
 	meta       map[string]interface{}
 	resources  score.WorkloadResources
 	extensions extensions.HumanitecResourcesSpecs
+
+	// workloads are the workloads converted together, to resolve the 'workload' resources outputs (optional)
+	workloads map[string]*score.Workload
+
+	// location is the place in the source spec where the templates are being substituted
+	location   string
+	unresolved *[]UnresolvedReference
+	warnings   *[]Warning
+
+	// logger receives the diagnostic messages
+	logger *log.Logger
 }
 
 // buildContext initializes a new templatesContext instance
@@ -47,27 +96,77 @@ func buildContext(metadata score.WorkloadMetadata, resources score.WorkloadResou
 		meta:       metadataMap,
 		resources:  resources,
 		extensions: ext,
+		unresolved: &[]UnresolvedReference{},
+		warnings:   &[]Warning{},
+		logger:     discardLogger,
 	}, nil
 }
 
+// At returns a copy of the context that reports unresolved references at the given location.
+func (ctx *templatesContext) At(location string) *templatesContext {
+	var res = *ctx
+	res.location = location
+	return &res
+}
+
+// Warnf reports a conversion warning.
+func (ctx *templatesContext) Warnf(format string, args ...interface{}) {
+	*ctx.warnings = append(*ctx.warnings, Warning{Message: fmt.Sprintf(format, args...)})
+}
+
+// Warnings returns all the warnings reported so far, in order.
+func (ctx *templatesContext) Warnings() []Warning {
+	return append([]Warning{}, *ctx.warnings...)
+}
+
+// Unresolved returns all references that could not be resolved so far, sorted by location.
+func (ctx *templatesContext) Unresolved() []UnresolvedReference {
+	var res = append([]UnresolvedReference{}, *ctx.unresolved...)
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Location < res[j].Location
+	})
+	return res
+}
+
 // SubstituteAll replaces all matching '${...}' templates in map keys and string values recursively.
+// Nested maps and lists are processed at any depth.
 func (ctx *templatesContext) SubstituteAll(src map[string]interface{}) map[string]interface{} {
 	var dst = make(map[string]interface{}, 0)
 
 	for key, val := range src {
+		var valCtx = ctx.At(joinLocation(ctx.location, key))
 		key = ctx.Substitute(key)
-		switch v := val.(type) {
-		case string:
-			val = ctx.Substitute(v)
-		case map[string]interface{}:
-			val = ctx.SubstituteAll(v)
-		}
-		dst[key] = val
+		dst[key] = valCtx.substituteValue(val)
 	}
 
 	return dst
 }
 
+// substituteValue replaces all matching '${...}' templates in a value of any supported type.
+// Values of other types are returned as is.
+func (ctx *templatesContext) substituteValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case string:
+		return ctx.Substitute(v)
+	case map[string]interface{}:
+		return ctx.SubstituteAll(v)
+	case []interface{}:
+		var dst = make([]interface{}, len(v))
+		for i, item := range v {
+			dst[i] = ctx.At(fmt.Sprintf("%s[%d]", ctx.location, i)).substituteValue(item)
+		}
+		return dst
+	case []string:
+		var dst = make([]string, len(v))
+		for i, item := range v {
+			dst[i] = ctx.At(fmt.Sprintf("%s[%d]", ctx.location, i)).Substitute(item)
+		}
+		return dst
+	default:
+		return val
+	}
+}
+
 // Substitute replaces all matching '${...}' templates in a source string
 func (ctx *templatesContext) Substitute(src string) string {
 	return placeholderRegEx.ReplaceAllStringFunc(src, func(str string) string {
@@ -77,7 +176,7 @@ func (ctx *templatesContext) Substitute(src string) string {
 
 		// SANITY CHECK
 		if len(matches) != 3 {
-			log.Printf("Error: could not find a proper match in previously captured string fragment")
+			ctx.logger.Printf("Error: could not find a proper match in previously captured string fragment")
 			return src
 		}
 
@@ -99,7 +198,7 @@ func (ctx *templatesContext) Escape(src string) string {
 
 		// SANITY CHECK
 		if len(matches) != 3 {
-			log.Printf("Error: could not find a proper match in previously captured string fragment")
+			ctx.logger.Printf("Error: could not find a proper match in previously captured string fragment")
 			return src
 		}
 
@@ -119,7 +218,8 @@ func (ctx *templatesContext) mapVar(ref string) string {
 	}
 
 	var segments = strings.SplitN(ref, ".", 2)
-	switch segments[0] {
+	var namespace = segments[0]
+	switch namespace {
 	case "metadata":
 		if len(segments) == 2 {
 			if val, exists := ctx.meta[segments[1]]; exists {
@@ -132,6 +232,17 @@ func (ctx *templatesContext) mapVar(ref string) string {
 			segments = strings.SplitN(segments[1], ".", 2)
 			var resName = segments[0]
 			if res, exists := ctx.resources[resName]; exists {
+				if res.Type == "workload" {
+					var propName string
+					if len(segments) == 2 {
+						propName = segments[1]
+					}
+					if val, resolved := ctx.mapWorkloadVar(resName, propName); resolved {
+						return val
+					}
+					break
+				}
+
 				var source string
 				switch res.Type {
 				case "environment":
@@ -139,9 +250,6 @@ func (ctx *templatesContext) mapVar(ref string) string {
 				case "service":
 					source = fmt.Sprintf("modules.%s", resName)
 				default:
-					if res.Type == "workload" {
-						log.Println("Warning: 'workload' is a reserved resource type. Its usage may lead to compatibility issues with future releases of this application.")
-					}
 					resAnnotations, _ := res.Metadata["annotations"].(map[string]interface{})
 					resId, hasAnnotation := resAnnotations[AnnotationLabelResourceId].(string)
 					// DEPRECATED: Should use resource annotations instead
@@ -154,6 +262,14 @@ func (ctx *templatesContext) mapVar(ref string) string {
 					}
 					// END (DEPRECATED)
 
+					// Resources of the current workload are referenced without the module prefix
+					if mod, scope, id, err := parseResourceId(resId); err == nil && id != "" && (mod == "" || mod == ctx.meta["name"]) {
+						if scope == "" {
+							scope = "externals"
+						}
+						resId = fmt.Sprintf("%s.%s", scope, id)
+					}
+
 					if hasAnnotation && strings.HasPrefix(resId, "shared.") && (res.Class != nil && *res.Class != "" && *res.Class != "default") {
 						resId = resId + "-class-" + *res.Class
 					}
@@ -182,6 +298,49 @@ func (ctx *templatesContext) mapVar(ref string) string {
 		}
 	}
 
-	log.Printf("Warning: Can not resolve '%s'. Resource or property is not declared.", ref)
+	// Only SCORE references are tracked, other placeholders are resolved by Humanitec
+	if namespace == "metadata" || namespace == "resources" {
+		*ctx.unresolved = append(*ctx.unresolved, UnresolvedReference{
+			Location: ctx.location,
+			Ref:      ref,
+		})
+	}
 	return fmt.Sprintf("${%s}", ref)
 }
+
+// mapWorkloadVar resolves the outputs of a 'workload' resource, named after the workload it depends on:
+//
+//	${resources.{name}}                the workload module
+//	${resources.{name}.name}           the workload name
+//	${resources.{name}.host}           the workload service DNS name
+//	${resources.{name}.port}           the workload service port
+//	${resources.{name}.ports.{port}}   the workload service port by name (workloads converted together only)
+//
+// Returns false if the output is not supported, or if the port is not known.
+func (ctx *templatesContext) mapWorkloadVar(name, prop string) (string, bool) {
+	switch {
+	case prop == "":
+		return fmt.Sprintf("modules.%s", name), true
+	case prop == "name":
+		return name, true
+	case prop == "host":
+		return fmt.Sprintf("${modules.%s.service.name}", name), true
+	case prop == "port":
+		return fmt.Sprintf("${modules.%s.service.port}", name), true
+	case strings.HasPrefix(prop, "ports."):
+		if w, known := ctx.workloads[name]; known && w.Service != nil {
+			if port, exists := w.Service.Ports[strings.TrimPrefix(prop, "ports.")]; exists {
+				return fmt.Sprintf("%d", port.Port), true
+			}
+		}
+	}
+	return "", false
+}
+
+// joinLocation appends a key to the location path.
+func joinLocation(location, key string) string {
+	if location == "" {
+		return key
+	}
+	return location + "." + key
+}
@@ -34,6 +34,27 @@ func TestMapVar(t *testing.T) {
 		"service-a": score.Resource{
 			Type: "service",
 		},
+		"other-db": score.Resource{
+			Metadata: score.ResourceMetadata{
+				"annotations": map[string]interface{}{AnnotationLabelResourceId: "modules.other.externals.db"},
+			},
+			Type: "postgres",
+		},
+		"own-db": score.Resource{
+			Metadata: score.ResourceMetadata{
+				"annotations": map[string]interface{}{AnnotationLabelResourceId: "modules.test-name.externals.main-db"},
+			},
+			Type: "postgres",
+		},
+		"backend": score.Resource{
+			Type: "workload",
+		},
+		"id-db": score.Resource{
+			Metadata: score.ResourceMetadata{
+				"annotations": map[string]interface{}{AnnotationLabelResourceId: "orders-db"},
+			},
+			Type: "postgres",
+		},
 	}
 
 	var ext = extensions.HumanitecResourcesSpecs{
@@ -42,6 +63,16 @@ func TestMapVar(t *testing.T) {
 
 	ctx, err := buildContext(meta, resources, ext)
 	assert.NoError(t, err)
+	ctx.workloads = map[string]*score.Workload{
+		"backend": {
+			Service: &score.WorkloadService{
+				Ports: score.WorkloadServicePorts{
+					"www":     score.ServicePort{Port: 80},
+					"metrics": score.ServicePort{Port: 9090},
+				},
+			},
+		},
+	}
 
 	assert.Equal(t, "", ctx.mapVar(""))
 	assert.Equal(t, "$", ctx.mapVar("$"))
@@ -60,6 +91,16 @@ func TestMapVar(t *testing.T) {
 	assert.Equal(t, "${externals.db.nil}", ctx.mapVar("resources.db.nil"))
 	assert.Equal(t, "${modules.service-a.service.name}", ctx.mapVar("resources.service-a.name"))
 	assert.Equal(t, "${modules.service-a.service.port}", ctx.mapVar("resources.service-a.port"))
+	assert.Equal(t, "${modules.other.externals.db.host}", ctx.mapVar("resources.other-db.host"))
+	assert.Equal(t, "${externals.main-db.host}", ctx.mapVar("resources.own-db.host"))
+	assert.Equal(t, "${externals.orders-db.host}", ctx.mapVar("resources.id-db.host"))
+	assert.Equal(t, "modules.backend", ctx.mapVar("resources.backend"))
+	assert.Equal(t, "backend", ctx.mapVar("resources.backend.name"))
+	assert.Equal(t, "${modules.backend.service.name}", ctx.mapVar("resources.backend.host"))
+	assert.Equal(t, "${modules.backend.service.port}", ctx.mapVar("resources.backend.port"))
+	assert.Equal(t, "9090", ctx.mapVar("resources.backend.ports.metrics"))
+	assert.Equal(t, "${resources.backend.ports.nil}", ctx.mapVar("resources.backend.ports.nil"))
+	assert.Equal(t, "${resources.backend.nil}", ctx.mapVar("resources.backend.nil"))
 	assert.Equal(t, "${resources.nil}", ctx.mapVar("resources.nil"))
 	assert.Equal(t, "${nil.db.name}", ctx.mapVar("nil.db.name"))
 }
@@ -223,3 +264,94 @@ func TestSubstituteAll(t *testing.T) {
 
 	assert.Equal(t, expected, ctx.SubstituteAll(source))
 }
+
+func TestSubstituteAll_lists(t *testing.T) {
+	var meta = score.WorkloadMetadata{
+		"name": "test-name",
+	}
+
+	var resources = score.WorkloadResources{
+		"dns": score.Resource{
+			Type: "dns",
+		},
+		"service-a": score.Resource{
+			Type: "service",
+		},
+	}
+
+	var ext = extensions.HumanitecResourcesSpecs{
+		"dns": {Scope: "shared"},
+	}
+
+	ctx, err := buildContext(meta, resources, ext)
+	assert.NoError(t, err)
+
+	var source = map[string]interface{}{
+		"hosts": []interface{}{"${resources.dns.host}", "www.${resources.dns.host}", "$${resources.dns.host}"},
+		"tls": []interface{}{
+			map[string]interface{}{
+				"hosts":      []interface{}{"${resources.dns.host}"},
+				"secretName": "${metadata.name}-tls",
+			},
+		},
+		"matrix": []interface{}{
+			[]interface{}{"${resources.service-a.port}", 8080, true},
+		},
+		"args":  []string{"--name", "${metadata.name}"},
+		"empty": []interface{}{},
+	}
+
+	var expected = map[string]interface{}{
+		"hosts": []interface{}{"${shared.dns.host}", "www.${shared.dns.host}", "${resources.dns.host}"},
+		"tls": []interface{}{
+			map[string]interface{}{
+				"hosts":      []interface{}{"${shared.dns.host}"},
+				"secretName": "test-name-tls",
+			},
+		},
+		"matrix": []interface{}{
+			[]interface{}{"${modules.service-a.service.port}", 8080, true},
+		},
+		"args":  []string{"--name", "test-name"},
+		"empty": []interface{}{},
+	}
+
+	assert.Equal(t, expected, ctx.SubstituteAll(source))
+
+	ctx.At("spec").SubstituteAll(map[string]interface{}{
+		"tls": []interface{}{
+			map[string]interface{}{"hosts": []interface{}{"${resources.nil.host}"}},
+		},
+	})
+	assert.Equal(t, []UnresolvedReference{
+		{Location: "spec.tls[0].hosts[0]", Ref: "resources.nil.host"},
+	}, ctx.Unresolved())
+}
+
+func TestUnresolved(t *testing.T) {
+	var meta = score.WorkloadMetadata{
+		"name": "test-name",
+	}
+
+	var resources = score.WorkloadResources{
+		"db": score.Resource{
+			Type: "postgres",
+		},
+	}
+
+	ctx, err := buildContext(meta, resources, nil)
+	assert.NoError(t, err)
+
+	ctx.At("containers.backend.variables.DB").Substitute("${resources.db.host}:${resources.nil.port}")
+	ctx.At("extensions.spec").SubstituteAll(map[string]interface{}{
+		"labels": map[string]interface{}{
+			"name":  "${metadata.nil}",
+			"level": "${pod.debug.level}",
+		},
+	})
+
+	assert.Equal(t, []UnresolvedReference{
+		{Location: "containers.backend.variables.DB", Ref: "resources.nil.port"},
+		{Location: "extensions.spec.labels.name", Ref: "metadata.nil"},
+	}, ctx.Unresolved())
+}
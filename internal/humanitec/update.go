@@ -0,0 +1,223 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+// AnnotationManagedElsewhere is the workload annotation listing the module fields managed by other tools, as
+// comma-separated JSON pointers relative to the module (e.g. '/spec/replicas,/spec/containers/main/variables/EXTRA').
+// Updates never touch these fields.
+const AnnotationManagedElsewhere = "score.humanitec.io/managed-elsewhere"
+
+// UpdateModules replaces the modules added by the delta with JSON Patch (RFC 6902) updates of the modules deployed
+// in the set. Only the values generated from the SCORE specs are added, replaced or removed: the values added to the
+// deployed modules by other tools are kept, as well as the fields listed by the AnnotationManagedElsewhere annotation.
+// The generated fields are recorded in the modules annotations, so that the ones the SCORE specs do not generate
+// anymore are removed by the next update. Nothing is removed from modules deployed without this record.
+// Modules not deployed yet are still added as a whole.
+func UpdateModules(delta *humanitec.CreateDeploymentDeltaRequest, set *humanitec.Set) (*humanitec.CreateDeploymentDeltaRequest, error) {
+	var res humanitec.CreateDeploymentDeltaRequest
+	if err := normalize(delta, &res); err != nil {
+		return nil, fmt.Errorf("copying deployment delta: %w", err)
+	}
+
+	var names = make([]string, 0, len(res.Modules.Add))
+	for name := range res.Modules.Add {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var after = res.Modules.Add[name]
+		var before map[string]interface{}
+		if deployed, exists := set.Modules[name]; exists {
+			if err := normalize(deployed, &before); err != nil {
+				return nil, fmt.Errorf("copying deployed module '%s': %w", name, err)
+			}
+		}
+
+		var managedElsewhere = append(managedElsewherePaths(before), managedElsewherePaths(after)...)
+		var generated []string
+		for _, path := range leafPaths(nil, after) {
+			if !isManagedElsewhere(path, managedElsewhere) {
+				generated = append(generated, path)
+			}
+		}
+		setModuleAnnotation(after, ownedPathsAnnotation, encodeList(generated))
+		if before == nil {
+			continue
+		}
+		delete(res.Modules.Add, name)
+
+		var owned = make(map[string]bool)
+		for _, path := range decodeList(moduleAnnotations(before)[ownedPathsAnnotation]) {
+			owned[path] = true
+		}
+
+		var actions []humanitec.UpdateAction
+		var changed = false
+		for _, change := range Diff(before, after) {
+			if isManagedElsewhere(change.Path, managedElsewhere) {
+				continue
+			}
+			if change.After == nil {
+				actions = append(actions, ownedRemovals(change.Path, change.Before, owned)...)
+				continue
+			}
+			actions = append(actions, humanitec.UpdateAction{
+				Operation: change.Operation(),
+				Path:      change.Path,
+				Value:     change.After,
+			})
+		}
+		for _, action := range actions {
+			if action.Path != ownedPathsPointer {
+				changed = true
+			}
+		}
+		if changed {
+			if res.Modules.Update == nil {
+				res.Modules.Update = make(map[string][]humanitec.UpdateAction)
+			}
+			res.Modules.Update[name] = actions
+		}
+	}
+	if len(res.Modules.Add) == 0 {
+		res.Modules.Add = nil
+	}
+
+	return &res, nil
+}
+
+// ownedPathsPointer is the JSON pointer of the module ownedPathsAnnotation annotation.
+var ownedPathsPointer = joinPointer("spec", "annotations", ownedPathsAnnotation)
+
+// ownedRemovals returns the removals of the value at the path, or of its nested values, that were generated from the
+// SCORE specs. Values added by other tools are kept.
+func ownedRemovals(path string, value interface{}, owned map[string]bool) []humanitec.UpdateAction {
+	var leaves = leafPaths(nil, value)
+	var all = len(leaves) > 0
+	for _, leaf := range leaves {
+		if !owned[path+leaf] {
+			all = false
+			break
+		}
+	}
+	if all {
+		return []humanitec.UpdateAction{{Operation: "remove", Path: path}}
+	}
+
+	var actions []humanitec.UpdateAction
+	if obj, isMap := value.(map[string]interface{}); isMap {
+		var keys = make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			actions = append(actions, ownedRemovals(path+joinPointer(key), obj[key], owned)...)
+		}
+	}
+	return actions
+}
+
+// leafPaths returns the JSON pointers of the values nested in the document, the way Diff compares them: maps are
+// walked key by key, while any other values (including lists and empty maps) are leaves. The ownedPathsAnnotation
+// annotation itself is skipped.
+func leafPaths(path []string, value interface{}) []string {
+	var obj, isMap = value.(map[string]interface{})
+	if !isMap || len(obj) == 0 {
+		var pointer = joinPointer(path...)
+		if pointer == ownedPathsPointer {
+			return nil
+		}
+		return []string{pointer}
+	}
+
+	var keys = make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var paths []string
+	for _, key := range keys {
+		var subPath = append(append(make([]string, 0, len(path)+1), path...), key)
+		paths = append(paths, leafPaths(subPath, obj[key])...)
+	}
+	return paths
+}
+
+// setModuleAnnotation sets the module workload annotation, creating the missing parents.
+func setModuleAnnotation(module map[string]interface{}, key, value string) {
+	var spec, isMap = module["spec"].(map[string]interface{})
+	if !isMap {
+		spec = make(map[string]interface{})
+		module["spec"] = spec
+	}
+	annotations, isMap := spec["annotations"].(map[string]interface{})
+	if !isMap {
+		annotations = make(map[string]interface{})
+		spec["annotations"] = annotations
+	}
+	annotations[key] = value
+}
+
+// managedElsewherePaths returns the JSON pointers listed by the module AnnotationManagedElsewhere annotation.
+func managedElsewherePaths(module map[string]interface{}) []string {
+	return splitPaths(moduleAnnotations(module)[AnnotationManagedElsewhere])
+}
+
+// encodeList encodes the values as a JSON array annotation value.
+func encodeList(values []string) string {
+	if values == nil {
+		values = []string{}
+	}
+	data, _ := json.Marshal(values)
+	return string(data)
+}
+
+// decodeList returns the values of a JSON array annotation value, or nil if it is not valid.
+func decodeList(value interface{}) []string {
+	var str, _ = value.(string)
+	var values []string
+	if err := json.Unmarshal([]byte(str), &values); err != nil {
+		return nil
+	}
+	return values
+}
+
+// splitPaths returns the JSON pointers listed by a comma-separated annotation value.
+func splitPaths(value interface{}) []string {
+	var str, _ = value.(string)
+
+	var paths []string
+	for _, path := range strings.Split(str, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, strings.TrimSuffix(path, "/"))
+		}
+	}
+	return paths
+}
+
+// isManagedElsewhere returns true if the path is one of the paths, or is nested in one of them.
+func isManagedElsewhere(path string, paths []string) bool {
+	for _, p := range paths {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+func TestUpdateModules(t *testing.T) {
+	var set = &humanitec.Set{
+		Modules: map[string]map[string]interface{}{
+			"backend": {
+				"profile": "humanitec/default-module",
+				"spec": map[string]interface{}{
+					"annotations": map[string]interface{}{
+						managedByAnnotation:        managedBy,
+						AnnotationManagedElsewhere: "/spec/containers/main/image, /spec/replicas",
+					},
+					"containers": map[string]interface{}{
+						"main": map[string]interface{}{
+							"id":    "main",
+							"image": "busybox:1.0",
+							"variables": map[string]interface{}{
+								"DEBUG": "false",
+								"EXTRA": "added by hand",
+							},
+						},
+					},
+					"replicas": 3,
+				},
+			},
+			"worker": {
+				"profile": "humanitec/default-module",
+				"spec": map[string]interface{}{
+					"annotations": map[string]interface{}{
+						managedByAnnotation:  managedBy,
+						ownedPathsAnnotation: `["/profile","/spec/annotations/humanitec.io~1managed-by","/spec/containers/main/files/~1etc~1app~1a,b.conf/mode","/spec/containers/main/files/~1etc~1app~1a,b.conf/value","/spec/containers/main/files/~1etc~1old/mode","/spec/containers/main/files/~1etc~1old/value","/spec/containers/main/variables/DEBUG","/spec/containers/main/variables/OLD"]`,
+					},
+					"containers": map[string]interface{}{
+						"main": map[string]interface{}{
+							"files": map[string]interface{}{
+								"/etc/app/a,b.conf": map[string]interface{}{"mode": "", "value": "a,b"},
+								"/etc/old":          map[string]interface{}{"mode": "", "value": "old"},
+							},
+							"variables": map[string]interface{}{
+								"DEBUG": "false",
+								"EXTRA": "added by hand",
+								"OLD":   "old",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var tests = []struct {
+		Name   string
+		Delta  *humanitec.CreateDeploymentDeltaRequest
+		Output *humanitec.CreateDeploymentDeltaRequest
+	}{
+		{
+			Name: "Should update deployed modules and add new ones",
+			Delta: &humanitec.CreateDeploymentDeltaRequest{
+				Metadata: humanitec.DeltaMetadata{EnvID: "development", Name: "test"},
+				Modules: humanitec.ModuleDeltas{
+					Add: map[string]map[string]interface{}{
+						"backend": {
+							"profile": "humanitec/default-module",
+							"spec": map[string]interface{}{
+								"annotations": map[string]interface{}{managedByAnnotation: managedBy},
+								"containers": map[string]interface{}{
+									"main": map[string]interface{}{
+										"id":    "main",
+										"image": "busybox:2.0",
+										"variables": map[string]interface{}{
+											"DEBUG": "true",
+											"PORT":  "8080",
+										},
+										"files": map[string]interface{}{
+											"/etc/config": map[string]interface{}{"mode": "", "value": "debug: true"},
+										},
+									},
+								},
+								"replicas": 1,
+							},
+							"externals": map[string]interface{}{
+								"db": map[string]interface{}{"type": "postgres", "class": "default"},
+							},
+						},
+						"frontend": {
+							"profile": "humanitec/default-module",
+						},
+					},
+				},
+				Shared: []humanitec.UpdateAction{
+					{Operation: "add", Path: "/dns", Value: map[string]interface{}{"type": "dns"}},
+				},
+			},
+			Output: &humanitec.CreateDeploymentDeltaRequest{
+				Metadata: humanitec.DeltaMetadata{EnvID: "development", Name: "test"},
+				Modules: humanitec.ModuleDeltas{
+					Add: map[string]map[string]interface{}{
+						"frontend": {
+							"profile": "humanitec/default-module",
+							"spec": map[string]interface{}{
+								"annotations": map[string]interface{}{ownedPathsAnnotation: `["/profile"]`},
+							},
+						},
+					},
+					Update: map[string][]humanitec.UpdateAction{
+						"backend": {
+							{Operation: "add", Path: "/externals", Value: map[string]interface{}{
+								"db": map[string]interface{}{"type": "postgres", "class": "default"},
+							}},
+							{Operation: "add", Path: "/spec/annotations/humanitec.io~1owned-paths", Value: `["/externals/db/class","/externals/db/type","/profile","/spec/annotations/humanitec.io~1managed-by","/spec/containers/main/files/~1etc~1config/mode","/spec/containers/main/files/~1etc~1config/value","/spec/containers/main/id","/spec/containers/main/variables/DEBUG","/spec/containers/main/variables/PORT"]`},
+							{Operation: "add", Path: "/spec/containers/main/files", Value: map[string]interface{}{
+								"/etc/config": map[string]interface{}{"mode": "", "value": "debug: true"},
+							}},
+							{Operation: "replace", Path: "/spec/containers/main/variables/DEBUG", Value: "true"},
+							{Operation: "add", Path: "/spec/containers/main/variables/PORT", Value: "8080"},
+						},
+					},
+				},
+				Shared: []humanitec.UpdateAction{
+					{Operation: "add", Path: "/dns", Value: map[string]interface{}{"type": "dns"}},
+				},
+			},
+		},
+		{
+			Name: "Should skip up to date modules",
+			Delta: &humanitec.CreateDeploymentDeltaRequest{
+				Modules: humanitec.ModuleDeltas{
+					Add: map[string]map[string]interface{}{
+						"backend": {
+							"profile": "humanitec/default-module",
+							"spec": map[string]interface{}{
+								"containers": map[string]interface{}{
+									"main": map[string]interface{}{
+										"variables": map[string]interface{}{"DEBUG": "false"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Output: &humanitec.CreateDeploymentDeltaRequest{},
+		},
+		{
+			Name: "Should remove the generated values only",
+			Delta: &humanitec.CreateDeploymentDeltaRequest{
+				Modules: humanitec.ModuleDeltas{
+					Add: map[string]map[string]interface{}{
+						"worker": {
+							"profile": "humanitec/default-module",
+							"spec": map[string]interface{}{
+								"annotations": map[string]interface{}{managedByAnnotation: managedBy},
+								"containers": map[string]interface{}{
+									"main": map[string]interface{}{
+										"files": map[string]interface{}{
+											"/etc/app/a,b.conf": map[string]interface{}{"mode": "", "value": "a,b"},
+										},
+										"variables": map[string]interface{}{"DEBUG": "false"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Output: &humanitec.CreateDeploymentDeltaRequest{
+				Modules: humanitec.ModuleDeltas{
+					Update: map[string][]humanitec.UpdateAction{
+						"worker": {
+							{Operation: "replace", Path: "/spec/annotations/humanitec.io~1owned-paths", Value: `["/profile","/spec/annotations/humanitec.io~1managed-by","/spec/containers/main/files/~1etc~1app~1a,b.conf/mode","/spec/containers/main/files/~1etc~1app~1a,b.conf/value","/spec/containers/main/variables/DEBUG"]`},
+							{Operation: "remove", Path: "/spec/containers/main/files/~1etc~1old"},
+							{Operation: "remove", Path: "/spec/containers/main/variables/OLD"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			res, err := UpdateModules(tt.Delta, set)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.Output, res)
+
+			// Updates must apply to the deployed set
+			_, err = ApplyDelta(set, res)
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,280 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+// ValidationProblem describes a single issue found in the source spec.
+type ValidationProblem struct {
+	Workload string
+	Location string
+	Message  string
+}
+
+// ValidationError is returned when the source spec does not pass the validation.
+type ValidationError struct {
+	Problems []ValidationProblem
+}
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("validation failed:")
+	for _, p := range e.Problems {
+		sb.WriteString(fmt.Sprintf("\n  - %s: %s", p.Location, p.Message))
+		if p.Workload != "" {
+			sb.WriteString(fmt.Sprintf(" (workload '%s')", p.Workload))
+		}
+	}
+	return sb.String()
+}
+
+// ValidateResources checks workloads resources against the organization resource types catalogue.
+// Every resource type must be known, resource params must match the type inputs schema,
+// and '${resources.<name>.<property>}' references must match the type outputs schema.
+// All found problems are reported together as a ValidationError.
+func ValidateResources(workloads []WorkloadSource, resTypes []humanitec.ResourceType) error {
+	var catalogue = make(map[string]*humanitec.ResourceType, len(resTypes))
+	for i := range resTypes {
+		catalogue[resTypes[i].Type] = &resTypes[i]
+	}
+
+	// The inputs schemas are compiled once per resource type
+	var inputsSchemas = make(map[string]*compiledSchema)
+
+	var problems []ValidationProblem
+	for _, w := range workloads {
+		var wName, _ = w.Spec.Metadata["name"].(string)
+		var report = func(location, format string, args ...interface{}) {
+			problems = append(problems, ValidationProblem{
+				Workload: wName,
+				Location: location,
+				Message:  fmt.Sprintf(format, args...),
+			})
+		}
+
+		// Resource types and params
+		//
+		var resNames = make([]string, 0, len(w.Spec.Resources))
+		for resName := range w.Spec.Resources {
+			resNames = append(resNames, resName)
+		}
+		sort.Strings(resNames)
+		for _, resName := range resNames {
+			var res = w.Spec.Resources[resName]
+			if isReservedResourceType(res.Type) {
+				continue
+			}
+			resType, known := catalogue[res.Type]
+			if !known {
+				report(fmt.Sprintf("resources.%s.type", resName), "unknown resource type '%s'", res.Type)
+				continue
+			}
+			if len(res.Params) > 0 && len(resType.InputsSchema) > 0 {
+				schema, compiled := inputsSchemas[res.Type]
+				if !compiled {
+					schema = compileSchema(resType.InputsSchema)
+					inputsSchemas[res.Type] = schema
+				}
+				for _, v := range schema.validate(res.Params) {
+					report(fmt.Sprintf("resources.%s.params", resName), "%s: %s", v.Location, v.Message)
+				}
+			}
+		}
+
+		// Resource properties references
+		//
+		for _, ref := range findResourceReferences(w) {
+			res, exists := w.Spec.Resources[ref.resName]
+			if !exists || ref.propName == "" || isReservedResourceType(res.Type) {
+				continue
+			}
+			resType, known := catalogue[res.Type]
+			if !known {
+				continue
+			}
+			if outputs, hasOutputs := outputProperties(resType.OutputsSchema); hasOutputs && !outputs[ref.propName] {
+				report(ref.location, "resource type '%s' has no output '%s' (used in '${%s}')", res.Type, ref.propName, ref.ref)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// isReservedResourceType returns true for resource types that are handled by the converter itself.
+func isReservedResourceType(resType string) bool {
+	return resType == "service" || resType == "environment" || resType == "workload"
+}
+
+// outputProperties returns the names of all values and secrets declared in the outputs schema.
+// Returns false if the schema declares no properties at all.
+func outputProperties(schema map[string]interface{}) (map[string]bool, bool) {
+	var res = make(map[string]bool)
+	for _, section := range []string{"values", "secrets"} {
+		if sectionSchema, ok := schema[section].(map[string]interface{}); ok {
+			if props, ok := sectionSchema["properties"].(map[string]interface{}); ok {
+				for name := range props {
+					res[name] = true
+				}
+			}
+		}
+	}
+	return res, len(res) > 0
+}
+
+// compiledSchema is a compiled JSON schema, or the reason why it could not be compiled.
+type compiledSchema struct {
+	schema *jsonschema.Schema
+	err    error
+}
+
+// compileSchema compiles the JSON schema. Compilation errors are reported by the validation.
+func compileSchema(schema map[string]interface{}) *compiledSchema {
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return &compiledSchema{err: err}
+	}
+	var compiler = jsonschema.NewCompiler()
+	if err := compiler.AddResource("schema.json", bytes.NewReader(raw)); err != nil {
+		return &compiledSchema{err: err}
+	}
+	compiled, err := compiler.Compile("schema.json")
+	return &compiledSchema{schema: compiled, err: err}
+}
+
+// validate validates the value against the JSON schema and returns all violations.
+func (s *compiledSchema) validate(value interface{}) []ValidationProblem {
+	if s.err != nil {
+		return []ValidationProblem{{Location: "/", Message: fmt.Sprintf("invalid schema: %v", s.err)}}
+	}
+
+	var doc interface{}
+	if err := normalize(value, &doc); err != nil {
+		return []ValidationProblem{{Location: "/", Message: err.Error()}}
+	}
+	return schemaViolations(s.schema.Validate(doc))
+}
+
+// schemaViolations lists the most specific JSON schema violations with their JSON pointer locations.
+// The violations are sorted by location.
+func schemaViolations(err error) []ValidationProblem {
+	var verr *jsonschema.ValidationError
+	if err == nil {
+		return nil
+	} else if !errors.As(err, &verr) {
+		return []ValidationProblem{{Location: "/", Message: err.Error()}}
+	}
+
+	var res []ValidationProblem
+	var walk func(*jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			var location = e.InstanceLocation
+			if location == "" {
+				location = "/"
+			}
+			res = append(res, ValidationProblem{Location: location, Message: e.Message})
+		}
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(verr)
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Location < res[j].Location || (res[i].Location == res[j].Location && res[i].Message < res[j].Message)
+	})
+	return res
+}
+
+// resourceReference is a '${resources.<name>.<property>}' reference found in the source spec.
+type resourceReference struct {
+	location string
+	ref      string
+	resName  string
+	propName string
+}
+
+// findResourceReferences lists all resources references used by the workload, ordered by location.
+func findResourceReferences(w WorkloadSource) []resourceReference {
+	var res []resourceReference
+	var scan = func(location, src string) {
+		for _, matches := range placeholderRegEx.FindAllStringSubmatch(src, -1) {
+			var segments = strings.SplitN(matches[2], ".", 4)
+			if len(segments) < 2 || segments[0] != "resources" {
+				continue
+			}
+			var ref = resourceReference{location: location, ref: matches[2], resName: segments[1]}
+			if len(segments) > 2 {
+				ref.propName = segments[2]
+			}
+			res = append(res, ref)
+		}
+	}
+	var scanAll func(location string, val interface{})
+	scanAll = func(location string, val interface{}) {
+		switch v := val.(type) {
+		case string:
+			scan(location, v)
+		case map[string]interface{}:
+			for key, item := range v {
+				scan(location, key)
+				scanAll(joinLocation(location, key), item)
+			}
+		case []interface{}:
+			for i, item := range v {
+				scanAll(fmt.Sprintf("%s[%d]", location, i), item)
+			}
+		}
+	}
+
+	for cName, c := range w.Spec.Containers {
+		for key, val := range c.Variables {
+			scan(fmt.Sprintf("containers.%s.variables.%s", cName, key), val)
+		}
+		for _, f := range c.Files {
+			if f.NoExpand != nil && *f.NoExpand {
+				continue
+			}
+			var content string
+			if f.Content != nil {
+				content = *f.Content
+			} else if f.Source != nil {
+				content, _ = w.readFile(*f.Source)
+			}
+			scan(fmt.Sprintf("containers.%s.files.%s", cName, f.Target), content)
+		}
+		for _, vol := range c.Volumes {
+			scan(fmt.Sprintf("containers.%s.volumes.%s", cName, vol.Target), vol.Source)
+		}
+	}
+	for resName, r := range w.Spec.Resources {
+		scanAll(fmt.Sprintf("resources.%s.params", resName), r.Params)
+	}
+	if w.Extensions != nil {
+		scanAll("extensions.spec", w.Extensions.Spec)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].location < res[j].location || (res[i].location == res[j].location && res[i].ref < res[j].ref)
+	})
+	return res
+}
@@ -0,0 +1,158 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"testing"
+
+	score "github.com/score-spec/score-go/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/score-spec/score-humanitec/internal/humanitec/extensions"
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+func TestValidateResources(t *testing.T) {
+	var resTypes = []humanitec.ResourceType{
+		{
+			Type: "postgres",
+			InputsSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"extensions": map[string]interface{}{"type": "object"},
+				},
+				"additionalProperties": false,
+			},
+			OutputsSchema: map[string]interface{}{
+				"values": map[string]interface{}{
+					"properties": map[string]interface{}{
+						"host": map[string]interface{}{"type": "string"},
+						"port": map[string]interface{}{"type": "integer"},
+					},
+				},
+				"secrets": map[string]interface{}{
+					"properties": map[string]interface{}{
+						"password": map[string]interface{}{"type": "string"},
+					},
+				},
+			},
+		},
+		{
+			Type: "dns",
+		},
+	}
+
+	var tests = []struct {
+		Name      string
+		Workloads []WorkloadSource
+		Error     string
+	}{
+		{
+			Name: "Should accept known resource types",
+			Workloads: []WorkloadSource{
+				{
+					Spec: &score.Workload{
+						Metadata: score.WorkloadMetadata{"name": "backend"},
+						Containers: score.WorkloadContainers{
+							"backend": score.Container{
+								Variables: map[string]string{
+									"DB": "postgresql://${resources.db.host}:${resources.db.port}",
+									"PW": "${resources.db.password}",
+								},
+							},
+						},
+						Resources: map[string]score.Resource{
+							"db":  {Type: "postgres", Params: map[string]interface{}{"extensions": map[string]interface{}{}}},
+							"dns": {Type: "dns", Params: map[string]interface{}{"anything": "${resources.dns.anything}"}},
+							"env": {Type: "environment"},
+							"api": {Type: "service"},
+						},
+					},
+					Extensions: &extensions.HumanitecExtensionsSpec{
+						Spec: map[string]interface{}{
+							"labels": map[string]interface{}{"url": "${resources.dns.host}"},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "Should report all problems",
+			Workloads: []WorkloadSource{
+				{
+					Spec: &score.Workload{
+						Metadata: score.WorkloadMetadata{"name": "backend"},
+						Containers: score.WorkloadContainers{
+							"backend": score.Container{
+								Variables: map[string]string{
+									"DB": "${resources.db.hots}",
+								},
+							},
+						},
+						Resources: map[string]score.Resource{
+							"db":    {Type: "postgres", Params: map[string]interface{}{"extension": "uuid", "extensions": "uuid"}},
+							"queue": {Type: "rabbitmq"},
+						},
+					},
+					Extensions: &extensions.HumanitecExtensionsSpec{
+						Spec: map[string]interface{}{
+							"labels": []interface{}{"${resources.db.nme}"},
+						},
+					},
+				},
+			},
+			Error: `validation failed:
+  - resources.db.params: /: additionalProperties 'extension' not allowed (workload 'backend')
+  - resources.db.params: /extensions: expected object, but got string (workload 'backend')
+  - resources.queue.type: unknown resource type 'rabbitmq' (workload 'backend')
+  - containers.backend.variables.DB: resource type 'postgres' has no output 'hots' (used in '${resources.db.hots}') (workload 'backend')
+  - extensions.spec.labels[0]: resource type 'postgres' has no output 'nme' (used in '${resources.db.nme}') (workload 'backend')`,
+		},
+		{
+			Name: "Should validate the params of all the resources of the same type",
+			Workloads: []WorkloadSource{
+				{
+					Spec: &score.Workload{
+						Metadata: score.WorkloadMetadata{"name": "backend"},
+						Resources: map[string]score.Resource{
+							"db":    {Type: "postgres", Params: map[string]interface{}{"extensions": map[string]interface{}{}}},
+							"cache": {Type: "postgres", Params: map[string]interface{}{"extensions": "uuid"}},
+						},
+					},
+				},
+				{
+					Spec: &score.Workload{
+						Metadata: score.WorkloadMetadata{"name": "worker"},
+						Resources: map[string]score.Resource{
+							"db": {Type: "postgres", Params: map[string]interface{}{"extension": "uuid"}},
+						},
+					},
+				},
+			},
+			Error: `validation failed:
+  - resources.cache.params: /extensions: expected object, but got string (workload 'backend')
+  - resources.db.params: /: additionalProperties 'extension' not allowed (workload 'worker')`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			err := ValidateResources(tt.Workloads, resTypes)
+
+			if tt.Error != "" {
+				// On Error
+				//
+				assert.EqualError(t, err, tt.Error)
+			} else {
+				// On Success
+				//
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -23,19 +23,37 @@ type apiClient struct {
 	baseUrl            string
 	token              string
 	humanitecUserAgent string
+	retryPolicy        RetryPolicy
+	tracer             *Tracer
 
 	client *rest.Client
 }
 
+// ClientOption configures optional settings of the Humanitec API client.
+type ClientOption func(*apiClient)
+
+// WithRetryPolicy sets the policy for retrying transient API errors.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(api *apiClient) {
+		api.retryPolicy = policy
+	}
+}
+
 // NewClient constructs new Humanitec API client.
-func NewClient(url, token string, httpClient *http.Client) (Client, error) {
-	return &apiClient{
+// Uses DefaultRetryPolicy unless configured otherwise.
+func NewClient(url, token string, httpClient *http.Client, opts ...ClientOption) (Client, error) {
+	var api = &apiClient{
 		baseUrl:            url,
 		token:              token,
 		humanitecUserAgent: fmt.Sprintf("app %s; sdk %s", ScoreUserAgent, ScoreUserAgent),
+		retryPolicy:        DefaultRetryPolicy(),
 
 		client: &rest.Client{
 			HTTPClient: httpClient,
 		},
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(api)
+	}
+	return api, nil
 }
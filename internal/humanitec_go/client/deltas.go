@@ -13,6 +13,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/sendgrid/rest"
 
@@ -40,9 +41,9 @@ func (api *apiClient) CreateDelta(ctx context.Context, orgID, appID string, delt
 		Body: data,
 	}
 
-	resp, err := api.client.SendWithContext(ctx, req)
+	resp, err := api.send(ctx, req, false)
 	if err != nil {
-		return nil, fmt.Errorf("humanitec api: %s %s: %w", req.Method, req.BaseURL, err)
+		return nil, err
 	}
 
 	switch resp.StatusCode {
@@ -85,9 +86,9 @@ func (api *apiClient) UpdateDelta(ctx context.Context, orgID string, appID strin
 		Body: buf.Bytes(),
 	}
 
-	resp, err := api.client.SendWithContext(ctx, req)
+	resp, err := api.send(ctx, req, false)
 	if err != nil {
-		return nil, fmt.Errorf("humanitec api: %s %s: %w", req.Method, req.BaseURL, err)
+		return nil, err
 	}
 
 	switch resp.StatusCode {
@@ -104,6 +105,131 @@ func (api *apiClient) UpdateDelta(ctx context.Context, orgID string, appID strin
 	}
 }
 
-func resError(req rest.Request, resp *rest.Response) error {
-	return fmt.Errorf("humanitec api: %s %s: unexpected response status %d - %s\n%s", req.Method, req.BaseURL, resp.StatusCode, http.StatusText(resp.StatusCode), resp.Body)
+// GetDelta gets the Deployment Delta with the given deltaID.
+func (api *apiClient) GetDelta(ctx context.Context, orgID, appID, deltaID string) (*humanitec.DeploymentDelta, error) {
+	apiPath := fmt.Sprintf("/orgs/%s/apps/%s/deltas/%s", orgID, appID, deltaID)
+	req := rest.Request{
+		Method:  http.MethodGet,
+		BaseURL: api.baseUrl + apiPath,
+		Headers: map[string]string{
+			"Authorization":        "Bearer " + api.token,
+			"Accept":               "application/json",
+			"Humanitec-User-Agent": api.humanitecUserAgent,
+		},
+	}
+
+	resp, err := api.send(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		{
+			var res humanitec.DeploymentDelta
+			if err = json.Unmarshal([]byte(resp.Body), &res); err != nil {
+				return nil, fmt.Errorf("humanitec api: %s %s: parsing response: %w", req.Method, req.BaseURL, err)
+			}
+			return &res, nil
+		}
+
+	default:
+		return nil, resError(req, resp)
+	}
+}
+
+// ListDeltas lists the Deployment Deltas for the orgID and appID.
+// The list is filtered by the envID (if not empty) and by the archived state.
+func (api *apiClient) ListDeltas(ctx context.Context, orgID, appID, envID string, archived bool) ([]humanitec.DeploymentDelta, error) {
+	apiPath := fmt.Sprintf("/orgs/%s/apps/%s/deltas", orgID, appID)
+	req := rest.Request{
+		Method:  http.MethodGet,
+		BaseURL: api.baseUrl + apiPath,
+		Headers: map[string]string{
+			"Authorization":        "Bearer " + api.token,
+			"Accept":               "application/json",
+			"Humanitec-User-Agent": api.humanitecUserAgent,
+		},
+		QueryParams: map[string]string{
+			"archived": strconv.FormatBool(archived),
+		},
+	}
+	if envID != "" {
+		req.QueryParams["env"] = envID
+	}
+
+	resp, err := api.send(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		{
+			var res []humanitec.DeploymentDelta
+			if err = json.Unmarshal([]byte(resp.Body), &res); err != nil {
+				return nil, fmt.Errorf("humanitec api: %s %s: parsing response: %w", req.Method, req.BaseURL, err)
+			}
+			return res, nil
+		}
+
+	default:
+		return nil, resError(req, resp)
+	}
+}
+
+// ArchiveDelta marks the Deployment Delta with the given deltaID as archived.
+func (api *apiClient) ArchiveDelta(ctx context.Context, orgID, appID, deltaID string) error {
+	apiPath := fmt.Sprintf("/orgs/%s/apps/%s/deltas/%s/metadata/archived", orgID, appID, deltaID)
+	req := rest.Request{
+		Method:  http.MethodPut,
+		BaseURL: api.baseUrl + apiPath,
+		Headers: map[string]string{
+			"Authorization":        "Bearer " + api.token,
+			"Content-Type":         "application/json",
+			"Accept":               "application/json",
+			"Humanitec-User-Agent": api.humanitecUserAgent,
+		},
+		Body: []byte("true"),
+	}
+
+	resp, err := api.send(ctx, req, false)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+
+	default:
+		return resError(req, resp)
+	}
+}
+
+// DeleteDelta deletes the Deployment Delta with the given deltaID.
+func (api *apiClient) DeleteDelta(ctx context.Context, orgID, appID, deltaID string) error {
+	apiPath := fmt.Sprintf("/orgs/%s/apps/%s/deltas/%s", orgID, appID, deltaID)
+	req := rest.Request{
+		Method:  http.MethodDelete,
+		BaseURL: api.baseUrl + apiPath,
+		Headers: map[string]string{
+			"Authorization":        "Bearer " + api.token,
+			"Accept":               "application/json",
+			"Humanitec-User-Agent": api.humanitecUserAgent,
+		},
+	}
+
+	resp, err := api.send(ctx, req, false)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+
+	default:
+		return resError(req, resp)
+	}
 }
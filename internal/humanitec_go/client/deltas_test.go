@@ -223,3 +223,198 @@ func TestUpdateDelta_fail(t *testing.T) {
 	assert.Nil(t, res)
 	assert.ErrorContains(t, err, ": unexpected response status 404 - Not Found\n{\"error\": \"Not Found\"}")
 }
+
+func TestGetDelta(t *testing.T) {
+	const (
+		orgID    = "test_org"
+		appID    = "test-app"
+		deltaID  = "0123456789abcdef0123456789abcdef"
+		apiToken = "qwe...rty"
+	)
+
+	var tests = []struct {
+		Name           string
+		StatusCode     int
+		Response       []byte
+		ExpectedResult *humanitec.DeploymentDelta
+		ExpectedError  error
+	}{
+		{
+			Name:       "Should return the Deployment Delta",
+			StatusCode: http.StatusOK,
+			Response: []byte(`{
+				"id": "0123456789abcdef0123456789abcdef",
+				"metadata": {"env_id": "test-env", "name": "Test delta", "contributers": ["a.user@example.com"], "created_at": "2020-05-22T14:58:07Z"}
+			}`),
+			ExpectedResult: &humanitec.DeploymentDelta{
+				ID: deltaID,
+				Metadata: humanitec.DeltaMetadata{
+					EnvID:        "test-env",
+					Name:         "Test delta",
+					Contributers: []string{"a.user@example.com"},
+					CreatedAt:    "2020-05-22T14:58:07Z",
+				},
+			},
+		},
+		{
+			Name:          "Should handle API errors",
+			StatusCode:    http.StatusNotFound,
+			Response:      []byte(`not found`),
+			ExpectedError: errors.New("unexpected response status 404 - Not Found\nnot found"),
+		},
+		{
+			Name:          "Should handle response parsing errors",
+			StatusCode:    http.StatusOK,
+			Response:      []byte(`{NOT A VALID JSON}`),
+			ExpectedError: errors.New("parsing response"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			fakeServer := httptest.NewServer(
+				http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						if r.Method == http.MethodGet && r.URL.Path == fmt.Sprintf("/orgs/%s/apps/%s/deltas/%s", orgID, appID, deltaID) {
+							assert.Equal(t, []string{"Bearer " + apiToken}, r.Header["Authorization"])
+							w.WriteHeader(tt.StatusCode)
+							w.Write(tt.Response)
+							return
+						}
+						w.WriteHeader(http.StatusNotFound)
+					},
+				),
+			)
+			defer fakeServer.Close()
+
+			client, err := NewClient(fakeServer.URL, apiToken, fakeServer.Client())
+			assert.NoError(t, err)
+			res, err := client.GetDelta(testutil.TestContext(), orgID, appID, deltaID)
+
+			if tt.ExpectedError != nil {
+				// On Error
+				assert.ErrorContains(t, err, tt.ExpectedError.Error())
+			} else {
+				// On Success
+				assert.NoError(t, err)
+				assert.Equal(t, tt.ExpectedResult, res)
+			}
+		})
+	}
+}
+
+func TestListDeltas(t *testing.T) {
+	const (
+		orgID    = "test_org"
+		appID    = "test-app"
+		apiToken = "qwe...rty"
+	)
+
+	var tests = []struct {
+		Name           string
+		EnvID          string
+		Archived       bool
+		ExpectedQuery  string
+		ExpectedResult []humanitec.DeploymentDelta
+	}{
+		{
+			Name:           "Should list active deltas",
+			ExpectedQuery:  "archived=false",
+			ExpectedResult: []humanitec.DeploymentDelta{{ID: "qwe...rty"}},
+		},
+		{
+			Name:           "Should filter by environment and archived state",
+			EnvID:          "test-env",
+			Archived:       true,
+			ExpectedQuery:  "archived=true&env=test-env",
+			ExpectedResult: []humanitec.DeploymentDelta{{ID: "qwe...rty"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			fakeServer := httptest.NewServer(
+				http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						if r.Method == http.MethodGet && r.URL.Path == fmt.Sprintf("/orgs/%s/apps/%s/deltas", orgID, appID) {
+							assert.Equal(t, tt.ExpectedQuery, r.URL.Query().Encode())
+							w.WriteHeader(http.StatusOK)
+							w.Write([]byte(`[{"id": "qwe...rty"}]`))
+							return
+						}
+						w.WriteHeader(http.StatusNotFound)
+					},
+				),
+			)
+			defer fakeServer.Close()
+
+			client, err := NewClient(fakeServer.URL, apiToken, fakeServer.Client())
+			assert.NoError(t, err)
+			res, err := client.ListDeltas(testutil.TestContext(), orgID, appID, tt.EnvID, tt.Archived)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.ExpectedResult, res)
+		})
+	}
+}
+
+func TestArchiveDelta(t *testing.T) {
+	const (
+		orgID    = "test_org"
+		appID    = "test-app"
+		deltaID  = "0123456789abcdef0123456789abcdef"
+		apiToken = "qwe...rty"
+	)
+
+	var archived = false
+	fakeServer := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPut && r.URL.Path == fmt.Sprintf("/orgs/%s/apps/%s/deltas/%s/metadata/archived", orgID, appID, deltaID) {
+					assert.Equal(t, []string{"application/json"}, r.Header["Content-Type"])
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&archived))
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				w.WriteHeader(http.StatusNotFound)
+			},
+		),
+	)
+	defer fakeServer.Close()
+
+	client, err := NewClient(fakeServer.URL, apiToken, fakeServer.Client())
+	assert.NoError(t, err)
+	assert.NoError(t, client.ArchiveDelta(testutil.TestContext(), orgID, appID, deltaID))
+	assert.True(t, archived)
+
+	err = client.ArchiveDelta(testutil.TestContext(), orgID, appID, "unknown")
+	assert.ErrorContains(t, err, "unexpected response status 404 - Not Found")
+}
+
+func TestDeleteDelta(t *testing.T) {
+	const (
+		orgID    = "test_org"
+		appID    = "test-app"
+		deltaID  = "0123456789abcdef0123456789abcdef"
+		apiToken = "qwe...rty"
+	)
+
+	fakeServer := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodDelete && r.URL.Path == fmt.Sprintf("/orgs/%s/apps/%s/deltas/%s", orgID, appID, deltaID) {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				w.WriteHeader(http.StatusNotFound)
+			},
+		),
+	)
+	defer fakeServer.Close()
+
+	client, err := NewClient(fakeServer.URL, apiToken, fakeServer.Client())
+	assert.NoError(t, err)
+	assert.NoError(t, client.DeleteDelta(testutil.TestContext(), orgID, appID, deltaID))
+
+	err = client.DeleteDelta(testutil.TestContext(), orgID, appID, "unknown")
+	assert.ErrorContains(t, err, "unexpected response status 404 - Not Found")
+}
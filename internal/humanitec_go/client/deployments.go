@@ -12,6 +12,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/sendgrid/rest"
@@ -19,10 +20,6 @@ import (
 	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
 )
 
-var (
-	retryDelay = 5 * time.Second
-)
-
 // StartDeployment starts a new Deployment.
 func (api *apiClient) StartDeployment(ctx context.Context, orgID, appID, envID string, retry bool, deployment *humanitec.StartDeploymentRequest) (*humanitec.Deployment, error) {
 	data, err := json.Marshal(deployment)
@@ -43,9 +40,9 @@ func (api *apiClient) StartDeployment(ctx context.Context, orgID, appID, envID s
 		Body: data,
 	}
 
-	resp, err := api.client.SendWithContext(ctx, req)
+	resp, err := api.send(ctx, req, retry)
 	if err != nil {
-		return nil, fmt.Errorf("humanitec api: %s %s: %w", req.Method, req.BaseURL, err)
+		return nil, err
 	}
 
 	switch resp.StatusCode {
@@ -59,14 +56,127 @@ func (api *apiClient) StartDeployment(ctx context.Context, orgID, appID, envID s
 			return &res, nil
 		}
 
-	case http.StatusConflict:
-		if retry {
-			time.Sleep(retryDelay)
-			return api.StartDeployment(ctx, orgID, appID, envID, retry, deployment)
+	default:
+		return nil, resError(req, resp)
+	}
+}
+
+// GetDeployment gets the Deployment with the given deploymentID.
+func (api *apiClient) GetDeployment(ctx context.Context, orgID, appID, envID, deploymentID string) (*humanitec.Deployment, error) {
+	apiPath := fmt.Sprintf("/orgs/%s/apps/%s/envs/%s/deploys/%s", orgID, appID, envID, deploymentID)
+	req := rest.Request{
+		Method:  http.MethodGet,
+		BaseURL: api.baseUrl + apiPath,
+		Headers: map[string]string{
+			"Authorization":        "Bearer " + api.token,
+			"Accept":               "application/json",
+			"Humanitec-User-Agent": api.humanitecUserAgent,
+		},
+	}
+
+	resp, err := api.send(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		{
+			var res humanitec.Deployment
+			if err = json.Unmarshal([]byte(resp.Body), &res); err != nil {
+				return nil, fmt.Errorf("humanitec api: %s %s: parsing response: %w", req.Method, req.BaseURL, err)
+			}
+			return &res, nil
 		}
 
-		fallthrough
 	default:
 		return nil, resError(req, resp)
 	}
 }
+
+// ListDeployments lists the Deployments of the envID Environment.
+func (api *apiClient) ListDeployments(ctx context.Context, orgID, appID, envID string) ([]humanitec.Deployment, error) {
+	apiPath := fmt.Sprintf("/orgs/%s/apps/%s/envs/%s/deploys", orgID, appID, envID)
+	req := rest.Request{
+		Method:  http.MethodGet,
+		BaseURL: api.baseUrl + apiPath,
+		Headers: map[string]string{
+			"Authorization":        "Bearer " + api.token,
+			"Accept":               "application/json",
+			"Humanitec-User-Agent": api.humanitecUserAgent,
+		},
+	}
+
+	resp, err := api.send(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		{
+			var res []humanitec.Deployment
+			if err = json.Unmarshal([]byte(resp.Body), &res); err != nil {
+				return nil, fmt.Errorf("humanitec api: %s %s: parsing response: %w", req.Method, req.BaseURL, err)
+			}
+			return res, nil
+		}
+
+	default:
+		return nil, resError(req, resp)
+	}
+}
+
+// PreviousSuccessfulDeployment returns the last succeeded Deployment before the most recent one.
+// The deployments are ordered by the creation time, regardless of the order in the list.
+func PreviousSuccessfulDeployment(deployments []humanitec.Deployment) (*humanitec.Deployment, error) {
+	if len(deployments) == 0 {
+		return nil, fmt.Errorf("no deployments found")
+	}
+
+	var sorted = make([]humanitec.Deployment, len(deployments))
+	copy(sorted, deployments)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Status == humanitec.DeploymentStatusSucceeded {
+			return &sorted[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no successful deployment found before '%s'", sorted[0].ID)
+}
+
+// DeploymentGetter is the part of the Client used by WaitForDeployment.
+type DeploymentGetter interface {
+	GetDeployment(ctx context.Context, orgID, appID, envID, deploymentID string) (*humanitec.Deployment, error)
+}
+
+// WaitForDeployment polls the Deployment with the given deploymentID until it reaches a terminal state.
+// The onStatus callback (optional) is invoked every time the deployment status changes.
+// Use the context deadline to limit the waiting time.
+func WaitForDeployment(ctx context.Context, client DeploymentGetter, orgID, appID, envID, deploymentID string, interval time.Duration, onStatus func(*humanitec.Deployment)) (*humanitec.Deployment, error) {
+	var lastStatus string
+	for {
+		res, err := client.GetDeployment(ctx, orgID, appID, envID, deploymentID)
+		if err != nil {
+			return nil, err
+		}
+		if res.Status != lastStatus {
+			lastStatus = res.Status
+			if onStatus != nil {
+				onStatus(res)
+			}
+		}
+		if res.IsCompleted() {
+			return res, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return res, fmt.Errorf("waiting for deployment '%s': %w", deploymentID, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
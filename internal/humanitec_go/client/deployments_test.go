@@ -8,6 +8,7 @@ The Apache Software Foundation (http://www.apache.org/).
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -197,3 +198,321 @@ func TestStartDeployment(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDeployment(t *testing.T) {
+	const (
+		orgID        = "test_org"
+		appID        = "test-app"
+		envID        = "test-env"
+		deploymentID = "0123456789abcdef"
+		apiToken     = "qwe...rty"
+	)
+
+	var tests = []struct {
+		Name           string
+		ApiUrl         string
+		StatusCode     int
+		Response       []byte
+		ExpectedResult *humanitec.Deployment
+		ExpectedError  error
+	}{
+		// Success Path
+		//
+		{
+			Name:       "Should return the Deployment",
+			StatusCode: http.StatusOK,
+			Response: []byte(`{
+				"id": "0123456789abcdef",
+				"env_id": "test-env",
+				"delta_id": "test-delta",
+				"comment": "Test deployment",
+				"status": "succeeded"
+			}`),
+			ExpectedResult: &humanitec.Deployment{
+				ID:      deploymentID,
+				EnvID:   "test-env",
+				DeltaID: "test-delta",
+				Comment: "Test deployment",
+				Status:  humanitec.DeploymentStatusSucceeded,
+			},
+		},
+		// Errors Handling
+		//
+		{
+			Name:          "Should handle request errors",
+			ApiUrl:        "bad URL",
+			ExpectedError: errors.New("unsupported protocol scheme"),
+		},
+		{
+			Name:          "Should handle API errors",
+			StatusCode:    http.StatusNotFound,
+			Response:      []byte(`error details`),
+			ExpectedError: errors.New("unexpected response status 404 - Not Found\nerror details"),
+		},
+		{
+			Name:          "Should handle response parsing errors",
+			StatusCode:    http.StatusOK,
+			Response:      []byte(`{NOT A VALID JSON}`),
+			ExpectedError: errors.New("parsing response"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			fakeServer := httptest.NewServer(
+				http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						switch r.URL.Path {
+						case fmt.Sprintf("/orgs/%s/apps/%s/envs/%s/deploys/%s", orgID, appID, envID, deploymentID):
+							if r.Method != http.MethodGet {
+								w.WriteHeader(http.StatusMethodNotAllowed)
+								return
+							}
+							assert.Equal(t, []string{"Bearer " + apiToken}, r.Header["Authorization"])
+							assert.Equal(t, []string{"application/json"}, r.Header["Accept"])
+							assert.Equal(t, []string{"app score-humanitec/0.0.0; sdk score-humanitec/0.0.0"}, r.Header["Humanitec-User-Agent"])
+							w.WriteHeader(tt.StatusCode)
+							if len(tt.Response) > 0 {
+								w.Write(tt.Response)
+							}
+							return
+						}
+						w.WriteHeader(http.StatusNotFound)
+					},
+				),
+			)
+			defer fakeServer.Close()
+
+			if tt.ApiUrl == "" {
+				tt.ApiUrl = fakeServer.URL
+			}
+
+			client, err := NewClient(tt.ApiUrl, apiToken, fakeServer.Client())
+			assert.NoError(t, err)
+
+			res, err := client.GetDeployment(testutil.TestContext(), orgID, appID, envID, deploymentID)
+
+			if tt.ExpectedError != nil {
+				// On Error
+				assert.ErrorContains(t, err, tt.ExpectedError.Error())
+			} else {
+				// On Success
+				assert.NoError(t, err)
+				assert.Equal(t, tt.ExpectedResult, res)
+			}
+		})
+	}
+}
+
+func TestWaitForDeployment(t *testing.T) {
+	const (
+		orgID        = "test_org"
+		appID        = "test-app"
+		envID        = "test-env"
+		deploymentID = "0123456789abcdef"
+		apiToken     = "qwe...rty"
+	)
+
+	var tests = []struct {
+		Name             string
+		Statuses         []string
+		Timeout          time.Duration
+		ExpectedStatus   string
+		ExpectedNotified []string
+		ExpectedError    error
+	}{
+		{
+			Name:             "Should wait until the deployment succeeds",
+			Statuses:         []string{"pending", "in progress", "in progress", "succeeded"},
+			ExpectedStatus:   humanitec.DeploymentStatusSucceeded,
+			ExpectedNotified: []string{"pending", "in progress", "succeeded"},
+		},
+		{
+			Name:             "Should wait until the deployment fails",
+			Statuses:         []string{"in progress", "failed"},
+			ExpectedStatus:   humanitec.DeploymentStatusFailed,
+			ExpectedNotified: []string{"in progress", "failed"},
+		},
+		{
+			Name:          "Should stop waiting when the context is done",
+			Statuses:      []string{"in progress"},
+			Timeout:       50 * time.Millisecond,
+			ExpectedError: errors.New("context deadline exceeded"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			request := 0
+			fakeServer := httptest.NewServer(
+				http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						if r.Method == http.MethodGet && r.URL.Path == fmt.Sprintf("/orgs/%s/apps/%s/envs/%s/deploys/%s", orgID, appID, envID, deploymentID) {
+							var status = tt.Statuses[len(tt.Statuses)-1]
+							if request < len(tt.Statuses) {
+								status = tt.Statuses[request]
+							}
+							request++
+							w.WriteHeader(http.StatusOK)
+							assert.NoError(t, json.NewEncoder(w).Encode(&humanitec.Deployment{ID: deploymentID, Status: status}))
+							return
+						}
+						w.WriteHeader(http.StatusNotFound)
+					},
+				),
+			)
+			defer fakeServer.Close()
+
+			client, err := NewClient(fakeServer.URL, apiToken, fakeServer.Client())
+			assert.NoError(t, err)
+
+			var ctx = testutil.TestContext()
+			if tt.Timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.Timeout)
+				defer cancel()
+			}
+
+			var notified []string
+			res, err := WaitForDeployment(ctx, client, orgID, appID, envID, deploymentID, time.Millisecond, func(d *humanitec.Deployment) {
+				notified = append(notified, d.Status)
+			})
+
+			if tt.ExpectedError != nil {
+				// On Error
+				assert.ErrorContains(t, err, tt.ExpectedError.Error())
+			} else {
+				// On Success
+				assert.NoError(t, err)
+				assert.Equal(t, tt.ExpectedStatus, res.Status)
+				assert.Equal(t, tt.ExpectedNotified, notified)
+			}
+		})
+	}
+}
+
+func TestListDeployments(t *testing.T) {
+	const (
+		orgID    = "test_org"
+		appID    = "test-app"
+		envID    = "test-env"
+		apiToken = "qwe...rty"
+	)
+
+	var tests = []struct {
+		Name           string
+		StatusCode     int
+		Response       []byte
+		ExpectedResult []humanitec.Deployment
+		ExpectedError  error
+	}{
+		{
+			Name:       "Should return the Deployments",
+			StatusCode: http.StatusOK,
+			Response:   []byte(`[{"id": "qwe...rty", "env_id": "test-env", "set_id": "test-set", "status": "succeeded"}]`),
+			ExpectedResult: []humanitec.Deployment{
+				{ID: "qwe...rty", EnvID: envID, SetID: "test-set", Status: "succeeded"},
+			},
+		},
+		{
+			Name:          "Should handle API errors",
+			StatusCode:    http.StatusNotFound,
+			Response:      []byte(`error details`),
+			ExpectedError: errors.New("unexpected response status 404 - Not Found\nerror details"),
+		},
+		{
+			Name:          "Should handle response parsing errors",
+			StatusCode:    http.StatusOK,
+			Response:      []byte(`{NOT A VALID JSON}`),
+			ExpectedError: errors.New("parsing response"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			fakeServer := httptest.NewServer(
+				http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						if r.Method == http.MethodGet && r.URL.Path == fmt.Sprintf("/orgs/%s/apps/%s/envs/%s/deploys", orgID, appID, envID) {
+							assert.Equal(t, []string{"Bearer " + apiToken}, r.Header["Authorization"])
+							w.WriteHeader(tt.StatusCode)
+							w.Write(tt.Response)
+							return
+						}
+						w.WriteHeader(http.StatusNotFound)
+					},
+				),
+			)
+			defer fakeServer.Close()
+
+			client, err := NewClient(fakeServer.URL, apiToken, fakeServer.Client())
+			assert.NoError(t, err)
+			res, err := client.ListDeployments(testutil.TestContext(), orgID, appID, envID)
+
+			if tt.ExpectedError != nil {
+				// On Error
+				assert.ErrorContains(t, err, tt.ExpectedError.Error())
+			} else {
+				// On Success
+				assert.NoError(t, err)
+				assert.Equal(t, tt.ExpectedResult, res)
+			}
+		})
+	}
+}
+
+func TestPreviousSuccessfulDeployment(t *testing.T) {
+	var now = time.Now()
+
+	var tests = []struct {
+		Name          string
+		Deployments   []humanitec.Deployment
+		ExpectedID    string
+		ExpectedError error
+	}{
+		{
+			Name: "Should skip the current and failed deployments",
+			Deployments: []humanitec.Deployment{
+				{ID: "d1", Status: humanitec.DeploymentStatusSucceeded, CreatedAt: now.Add(-3 * time.Hour)},
+				{ID: "d4", Status: humanitec.DeploymentStatusSucceeded, CreatedAt: now},
+				{ID: "d3", Status: humanitec.DeploymentStatusFailed, CreatedAt: now.Add(-1 * time.Hour)},
+				{ID: "d2", Status: humanitec.DeploymentStatusSucceeded, CreatedAt: now.Add(-2 * time.Hour)},
+			},
+			ExpectedID: "d2",
+		},
+		{
+			Name: "Should roll back a failed current deployment",
+			Deployments: []humanitec.Deployment{
+				{ID: "d2", Status: humanitec.DeploymentStatusFailed, CreatedAt: now},
+				{ID: "d1", Status: humanitec.DeploymentStatusSucceeded, CreatedAt: now.Add(-1 * time.Hour)},
+			},
+			ExpectedID: "d1",
+		},
+		{
+			Name: "Should fail if there is no previous successful deployment",
+			Deployments: []humanitec.Deployment{
+				{ID: "d2", Status: humanitec.DeploymentStatusSucceeded, CreatedAt: now},
+				{ID: "d1", Status: humanitec.DeploymentStatusFailed, CreatedAt: now.Add(-1 * time.Hour)},
+			},
+			ExpectedError: errors.New("no successful deployment found before 'd2'"),
+		},
+		{
+			Name:          "Should fail if there are no deployments",
+			ExpectedError: errors.New("no deployments found"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			res, err := PreviousSuccessfulDeployment(tt.Deployments)
+			if tt.ExpectedError != nil {
+				// On Error
+				assert.EqualError(t, err, tt.ExpectedError.Error())
+			} else {
+				// On Success
+				assert.NoError(t, err)
+				assert.Equal(t, tt.ExpectedID, res.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sendgrid/rest"
+
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+// GetEnvironment gets the Environment with the given envID.
+func (api *apiClient) GetEnvironment(ctx context.Context, orgID, appID, envID string) (*humanitec.Environment, error) {
+	apiPath := fmt.Sprintf("/orgs/%s/apps/%s/envs/%s", orgID, appID, envID)
+	req := rest.Request{
+		Method:  http.MethodGet,
+		BaseURL: api.baseUrl + apiPath,
+		Headers: map[string]string{
+			"Authorization":        "Bearer " + api.token,
+			"Accept":               "application/json",
+			"Humanitec-User-Agent": api.humanitecUserAgent,
+		},
+	}
+
+	resp, err := api.send(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		{
+			var res humanitec.Environment
+			if err = json.Unmarshal([]byte(resp.Body), &res); err != nil {
+				return nil, fmt.Errorf("humanitec api: %s %s: parsing response: %w", req.Method, req.BaseURL, err)
+			}
+			return &res, nil
+		}
+
+	default:
+		return nil, resError(req, resp)
+	}
+}
+
+// CreateEnvironment creates a new Environment in the appID.
+// The Environment starts from the Deployment specified by 'from_deploy_id' if provided.
+func (api *apiClient) CreateEnvironment(ctx context.Context, orgID, appID string, env *humanitec.CreateEnvironmentRequest) (*humanitec.Environment, error) {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling payload into JSON: %w", err)
+	}
+
+	apiPath := fmt.Sprintf("/orgs/%s/apps/%s/envs", orgID, appID)
+	req := rest.Request{
+		Method:  http.MethodPost,
+		BaseURL: api.baseUrl + apiPath,
+		Headers: map[string]string{
+			"Authorization":        "Bearer " + api.token,
+			"Content-Type":         "application/json",
+			"Accept":               "application/json",
+			"Humanitec-User-Agent": api.humanitecUserAgent,
+		},
+		Body: data,
+	}
+
+	resp, err := api.send(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		{
+			var res humanitec.Environment
+			if err = json.Unmarshal([]byte(resp.Body), &res); err != nil {
+				return nil, fmt.Errorf("humanitec api: %s %s: parsing response: %w", req.Method, req.BaseURL, err)
+			}
+			return &res, nil
+		}
+
+	default:
+		return nil, resError(req, resp)
+	}
+}
+
+// DeleteEnvironment deletes the Environment with the given envID.
+func (api *apiClient) DeleteEnvironment(ctx context.Context, orgID, appID, envID string) error {
+	apiPath := fmt.Sprintf("/orgs/%s/apps/%s/envs/%s", orgID, appID, envID)
+	req := rest.Request{
+		Method:  http.MethodDelete,
+		BaseURL: api.baseUrl + apiPath,
+		Headers: map[string]string{
+			"Authorization":        "Bearer " + api.token,
+			"Accept":               "application/json",
+			"Humanitec-User-Agent": api.humanitecUserAgent,
+		},
+	}
+
+	resp, err := api.send(ctx, req, false)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+
+	default:
+		return resError(req, resp)
+	}
+}
+
+// EnvironmentCloner is the part of the Client used by CloneEnvironment.
+type EnvironmentCloner interface {
+	GetEnvironment(ctx context.Context, orgID, appID, envID string) (*humanitec.Environment, error)
+	CreateEnvironment(ctx context.Context, orgID, appID string, env *humanitec.CreateEnvironmentRequest) (*humanitec.Environment, error)
+}
+
+// CloneEnvironment creates a new Environment with the envID, of the same type as the baseEnvID Environment,
+// and starting from the last Deployment of the baseEnvID Environment (if any).
+// The envName defaults to the envID if empty.
+func CloneEnvironment(ctx context.Context, client EnvironmentCloner, orgID, appID, baseEnvID, envID, envName string) (*humanitec.Environment, error) {
+	base, err := client.GetEnvironment(ctx, orgID, appID, baseEnvID)
+	if err != nil {
+		return nil, fmt.Errorf("getting base environment '%s': %w", baseEnvID, err)
+	}
+
+	if envName == "" {
+		envName = envID
+	}
+	var req = humanitec.CreateEnvironmentRequest{
+		ID:   envID,
+		Name: envName,
+		Type: base.Type,
+	}
+	if base.LastDeploy != nil {
+		req.FromDeployID = base.LastDeploy.ID
+	}
+	return client.CreateEnvironment(ctx, orgID, appID, &req)
+}
@@ -0,0 +1,298 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+	"github.com/score-spec/score-humanitec/internal/testutil"
+)
+
+func TestGetEnvironment(t *testing.T) {
+	const (
+		orgID    = "test_org"
+		appID    = "test-app"
+		envID    = "test-env"
+		apiToken = "qwe...rty"
+	)
+
+	var tests = []struct {
+		Name           string
+		ApiUrl         string
+		StatusCode     int
+		Response       []byte
+		ExpectedResult *humanitec.Environment
+		ExpectedError  error
+	}{
+		// Success Path
+		//
+		{
+			Name:       "Should return the Environment",
+			StatusCode: http.StatusOK,
+			Response: []byte(`{
+				"id": "test-env",
+				"name": "Test Environment",
+				"type": "development",
+				"last_deploy": { "id": "qwe...rty", "set_id": "test-set", "status": "succeeded" }
+			}`),
+			ExpectedResult: &humanitec.Environment{
+				ID:   envID,
+				Name: "Test Environment",
+				Type: "development",
+				LastDeploy: &humanitec.Deployment{
+					ID:     "qwe...rty",
+					SetID:  "test-set",
+					Status: "succeeded",
+				},
+			},
+		},
+		// Errors Handling
+		//
+		{
+			Name:          "Should handle request errors",
+			ApiUrl:        "bad URL",
+			ExpectedError: errors.New("unsupported protocol scheme"),
+		},
+		{
+			Name:          "Should handle API errors",
+			StatusCode:    http.StatusNotFound,
+			Response:      []byte(`error details`),
+			ExpectedError: errors.New("unexpected response status 404 - Not Found\nerror details"),
+		},
+		{
+			Name:          "Should handle response parsing errors",
+			StatusCode:    http.StatusOK,
+			Response:      []byte(`{NOT A VALID JSON}`),
+			ExpectedError: errors.New("parsing response"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			fakeServer := httptest.NewServer(
+				http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						switch r.URL.Path {
+						case fmt.Sprintf("/orgs/%s/apps/%s/envs/%s", orgID, appID, envID):
+							if r.Method != http.MethodGet {
+								w.WriteHeader(http.StatusMethodNotAllowed)
+								return
+							}
+							assert.Equal(t, []string{"Bearer " + apiToken}, r.Header["Authorization"])
+							assert.Equal(t, []string{"application/json"}, r.Header["Accept"])
+							assert.Equal(t, []string{"app score-humanitec/0.0.0; sdk score-humanitec/0.0.0"}, r.Header["Humanitec-User-Agent"])
+							w.WriteHeader(tt.StatusCode)
+							if len(tt.Response) > 0 {
+								w.Write(tt.Response)
+							}
+							return
+						}
+						w.WriteHeader(http.StatusNotFound)
+					},
+				),
+			)
+			defer fakeServer.Close()
+
+			if tt.ApiUrl == "" {
+				tt.ApiUrl = fakeServer.URL
+			}
+
+			client, err := NewClient(tt.ApiUrl, apiToken, fakeServer.Client())
+			assert.NoError(t, err)
+
+			res, err := client.GetEnvironment(testutil.TestContext(), orgID, appID, envID)
+
+			if tt.ExpectedError != nil {
+				// On Error
+				assert.ErrorContains(t, err, tt.ExpectedError.Error())
+			} else {
+				// On Success
+				assert.NoError(t, err)
+				assert.Equal(t, tt.ExpectedResult, res)
+			}
+		})
+	}
+}
+
+func TestCreateEnvironment(t *testing.T) {
+	const (
+		orgID    = "test_org"
+		appID    = "test-app"
+		apiToken = "qwe...rty"
+	)
+
+	var tests = []struct {
+		Name           string
+		Data           *humanitec.CreateEnvironmentRequest
+		StatusCode     int
+		Response       []byte
+		ExpectedResult *humanitec.Environment
+		ExpectedError  error
+	}{
+		{
+			Name:       "Should create the Environment",
+			Data:       &humanitec.CreateEnvironmentRequest{ID: "pr-123", Name: "pr-123", Type: "development", FromDeployID: "qwe...rty"},
+			StatusCode: http.StatusCreated,
+			Response:   []byte(`{"id": "pr-123", "name": "pr-123", "type": "development"}`),
+			ExpectedResult: &humanitec.Environment{
+				ID:   "pr-123",
+				Name: "pr-123",
+				Type: "development",
+			},
+		},
+		{
+			Name:          "Should handle API errors",
+			Data:          &humanitec.CreateEnvironmentRequest{ID: "pr-123"},
+			StatusCode:    http.StatusConflict,
+			Response:      []byte(`already exists`),
+			ExpectedError: errors.New("unexpected response status 409 - Conflict\nalready exists"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			fakeServer := httptest.NewServer(
+				http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						if r.Method == http.MethodPost && r.URL.Path == fmt.Sprintf("/orgs/%s/apps/%s/envs", orgID, appID) {
+							assert.Equal(t, []string{"application/json"}, r.Header["Content-Type"])
+							var body humanitec.CreateEnvironmentRequest
+							assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+							assert.Equal(t, tt.Data, &body)
+							w.WriteHeader(tt.StatusCode)
+							w.Write(tt.Response)
+							return
+						}
+						w.WriteHeader(http.StatusNotFound)
+					},
+				),
+			)
+			defer fakeServer.Close()
+
+			client, err := NewClient(fakeServer.URL, apiToken, fakeServer.Client())
+			assert.NoError(t, err)
+			res, err := client.CreateEnvironment(testutil.TestContext(), orgID, appID, tt.Data)
+
+			if tt.ExpectedError != nil {
+				// On Error
+				assert.ErrorContains(t, err, tt.ExpectedError.Error())
+			} else {
+				// On Success
+				assert.NoError(t, err)
+				assert.Equal(t, tt.ExpectedResult, res)
+			}
+		})
+	}
+}
+
+func TestDeleteEnvironment(t *testing.T) {
+	const (
+		orgID    = "test_org"
+		appID    = "test-app"
+		envID    = "pr-123"
+		apiToken = "qwe...rty"
+	)
+
+	fakeServer := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodDelete && r.URL.Path == fmt.Sprintf("/orgs/%s/apps/%s/envs/%s", orgID, appID, envID) {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				w.WriteHeader(http.StatusNotFound)
+			},
+		),
+	)
+	defer fakeServer.Close()
+
+	client, err := NewClient(fakeServer.URL, apiToken, fakeServer.Client())
+	assert.NoError(t, err)
+	assert.NoError(t, client.DeleteEnvironment(testutil.TestContext(), orgID, appID, envID))
+
+	err = client.DeleteEnvironment(testutil.TestContext(), orgID, appID, "unknown")
+	assert.True(t, IsNotFound(err))
+}
+
+func TestCloneEnvironment(t *testing.T) {
+	const (
+		orgID    = "test_org"
+		appID    = "test-app"
+		apiToken = "qwe...rty"
+	)
+
+	var tests = []struct {
+		Name            string
+		BaseEnv         []byte
+		ExpectedRequest *humanitec.CreateEnvironmentRequest
+		ExpectedError   error
+	}{
+		{
+			Name:            "Should clone the last deployment",
+			BaseEnv:         []byte(`{"id": "development", "type": "development", "last_deploy": {"id": "qwe...rty"}}`),
+			ExpectedRequest: &humanitec.CreateEnvironmentRequest{ID: "pr-123", Name: "pr-123", Type: "development", FromDeployID: "qwe...rty"},
+		},
+		{
+			Name:            "Should create an empty environment if base was never deployed",
+			BaseEnv:         []byte(`{"id": "development", "type": "development"}`),
+			ExpectedRequest: &humanitec.CreateEnvironmentRequest{ID: "pr-123", Name: "pr-123", Type: "development"},
+		},
+		{
+			Name:          "Should fail if base environment does not exist",
+			ExpectedError: errors.New("getting base environment 'development'"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var created *humanitec.CreateEnvironmentRequest
+			fakeServer := httptest.NewServer(
+				http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						switch {
+						case r.Method == http.MethodGet && r.URL.Path == fmt.Sprintf("/orgs/%s/apps/%s/envs/development", orgID, appID) && tt.BaseEnv != nil:
+							w.WriteHeader(http.StatusOK)
+							w.Write(tt.BaseEnv)
+						case r.Method == http.MethodPost && r.URL.Path == fmt.Sprintf("/orgs/%s/apps/%s/envs", orgID, appID):
+							created = &humanitec.CreateEnvironmentRequest{}
+							assert.NoError(t, json.NewDecoder(r.Body).Decode(created))
+							w.WriteHeader(http.StatusCreated)
+							json.NewEncoder(w).Encode(&humanitec.Environment{ID: created.ID, Name: created.Name, Type: created.Type})
+						default:
+							w.WriteHeader(http.StatusNotFound)
+						}
+					},
+				),
+			)
+			defer fakeServer.Close()
+
+			client, err := NewClient(fakeServer.URL, apiToken, fakeServer.Client())
+			assert.NoError(t, err)
+			res, err := CloneEnvironment(testutil.TestContext(), client, orgID, appID, "development", "pr-123", "")
+
+			if tt.ExpectedError != nil {
+				// On Error
+				assert.ErrorContains(t, err, tt.ExpectedError.Error())
+				assert.True(t, IsNotFound(err))
+			} else {
+				// On Success
+				assert.NoError(t, err)
+				assert.Equal(t, tt.ExpectedRequest, created)
+				assert.Equal(t, "pr-123", res.ID)
+			}
+		})
+	}
+}
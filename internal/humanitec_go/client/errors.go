@@ -0,0 +1,78 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/sendgrid/rest"
+)
+
+// APIError is returned by the client when the Humanitec API responds with an unexpected status.
+//
+// Use errors.As to access the details:
+//
+//	var apiErr *client.APIError
+//	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+//		...
+//	}
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+
+	// Code, Message and Details are parsed from the Humanitec error response body (if available),
+	// e.g. {"error": "API-002", "message": "Environment not found", "details": {...}}
+	Code    string
+	Message string
+	Details map[string]interface{}
+
+	// Body is the raw response body.
+	Body string
+
+	// Retryable is set if the request can be retried later with a chance of success.
+	Retryable bool
+}
+
+// Error returns the error message.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("humanitec api: %s %s: unexpected response status %d - %s\n%s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+// IsNotFound reports whether the error is an APIError with '404 Not Found' status.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// resError builds an APIError from the unexpected API response.
+func resError(req rest.Request, resp *rest.Response) error {
+	var apiErr = &APIError{
+		Method:     string(req.Method),
+		URL:        req.BaseURL,
+		StatusCode: resp.StatusCode,
+		Body:       resp.Body,
+		Retryable:  isRetryable(req, resp.StatusCode, false),
+	}
+
+	var body struct {
+		Error   string                 `json:"error"`
+		Message string                 `json:"message"`
+		Details map[string]interface{} `json:"details"`
+	}
+	if err := json.Unmarshal([]byte(resp.Body), &body); err == nil {
+		apiErr.Code = body.Error
+		apiErr.Message = body.Message
+		apiErr.Details = body.Details
+	}
+
+	return apiErr
+}
@@ -0,0 +1,95 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/score-spec/score-humanitec/internal/testutil"
+)
+
+func TestAPIError(t *testing.T) {
+	const (
+		orgID    = "test_org"
+		apiToken = "qwe...rty"
+	)
+
+	var tests = []struct {
+		Name          string
+		StatusCode    int
+		Response      []byte
+		ExpectedError *APIError
+	}{
+		{
+			Name:       "Should parse Humanitec error details",
+			StatusCode: http.StatusForbidden,
+			Response:   []byte(`{"error":"API-005","message":"Forbidden","details":{"role":"deployer"}}`),
+			ExpectedError: &APIError{
+				Method:     http.MethodGet,
+				StatusCode: http.StatusForbidden,
+				Code:       "API-005",
+				Message:    "Forbidden",
+				Details:    map[string]interface{}{"role": "deployer"},
+				Body:       `{"error":"API-005","message":"Forbidden","details":{"role":"deployer"}}`,
+			},
+		},
+		{
+			Name:       "Should keep non-JSON response body",
+			StatusCode: http.StatusNotFound,
+			Response:   []byte(`not found`),
+			ExpectedError: &APIError{
+				Method:     http.MethodGet,
+				StatusCode: http.StatusNotFound,
+				Body:       `not found`,
+			},
+		},
+		{
+			Name:       "Should report retryable errors",
+			StatusCode: http.StatusServiceUnavailable,
+			ExpectedError: &APIError{
+				Method:     http.MethodGet,
+				StatusCode: http.StatusServiceUnavailable,
+				Retryable:  true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			fakeServer := httptest.NewServer(
+				http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						w.WriteHeader(tt.StatusCode)
+						w.Write(tt.Response)
+					},
+				),
+			)
+			defer fakeServer.Close()
+
+			client, err := NewClient(fakeServer.URL, apiToken, fakeServer.Client(), WithRetryPolicy(RetryPolicy{}))
+			assert.NoError(t, err)
+			_, err = client.ListResourceTypes(testutil.TestContext(), orgID)
+
+			var apiErr *APIError
+			assert.True(t, errors.As(fmt.Errorf("wrapped: %w", err), &apiErr))
+			tt.ExpectedError.URL = fmt.Sprintf("%s/orgs/%s/resources/types", fakeServer.URL, orgID)
+			assert.Equal(t, tt.ExpectedError, apiErr)
+		})
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	var err = &APIError{Method: http.MethodGet, URL: "https://api.example.com/orgs/test_org", StatusCode: http.StatusUnauthorized, Body: "unauthorized"}
+	assert.EqualError(t, err, "humanitec api: GET https://api.example.com/orgs/test_org: unexpected response status 401 - Unauthorized\nunauthorized")
+}
@@ -31,9 +31,9 @@ func (api *apiClient) ListResourceTypes(ctx context.Context, orgID string) ([]hu
 		},
 	}
 
-	resp, err := api.client.SendWithContext(ctx, req)
+	resp, err := api.send(ctx, req, false)
 	if err != nil {
-		return nil, fmt.Errorf("humanitec api: %s %s: %w", req.Method, req.BaseURL, err)
+		return nil, err
 	}
 
 	switch resp.StatusCode {
@@ -0,0 +1,147 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sendgrid/rest"
+)
+
+const (
+	DefaultRetryMaxRetries   = 10
+	DefaultRetryMaxDuration  = 5 * time.Minute
+	DefaultRetryInitialDelay = 1 * time.Second
+	DefaultRetryMaxDelay     = 30 * time.Second
+)
+
+// RetryPolicy controls how the API calls are retried on transient errors.
+//
+// Requests are retried on '429 Too Many Requests' and '503 Service Unavailable' responses,
+// as well as on '502 Bad Gateway' and '504 Gateway Timeout' for idempotent requests.
+// The delay between the attempts grows exponentially (with jitter), unless the server
+// provides a 'Retry-After' header.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries after the first attempt. Zero disables retries.
+	MaxRetries int
+	// MaxDuration limits the total time spent on retries. Zero means no limit.
+	MaxDuration time.Duration
+	// InitialDelay is the delay before the first retry.
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between the attempts.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:   DefaultRetryMaxRetries,
+		MaxDuration:  DefaultRetryMaxDuration,
+		InitialDelay: DefaultRetryInitialDelay,
+		MaxDelay:     DefaultRetryMaxDelay,
+	}
+}
+
+// backoff returns the delay before the given retry attempt (starting from 1).
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	var delay = p.InitialDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	// Equal jitter: half of the delay is fixed, the other half is random
+	var half = delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// isRetryable reports whether the response status is worth retrying for the request.
+func isRetryable(req rest.Request, statusCode int, retryConflict bool) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		// The request might have been processed, so only retry when it is safe to do so
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+			return true
+		}
+	case http.StatusConflict:
+		return retryConflict
+	}
+	return false
+}
+
+// retryAfter parses the 'Retry-After' response header, which can either be a number of seconds or an HTTP date.
+func retryAfter(resp *rest.Response) (time.Duration, bool) {
+	var values = http.Header(resp.Headers).Values("Retry-After")
+	if len(values) == 0 {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(values[0]); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(values[0]); err == nil {
+		var delay = time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// send sends the request, retrying transient errors according to the client retry policy.
+// Conflict responses are retried as well if retryConflict is set.
+// The last response is returned once the attempts are exhausted, so that the caller can report it.
+func (api *apiClient) send(ctx context.Context, req rest.Request, retryConflict bool) (*rest.Response, error) {
+	var policy = api.retryPolicy
+	var deadline time.Time
+	if policy.MaxDuration > 0 {
+		deadline = time.Now().Add(policy.MaxDuration)
+	}
+
+	for attempt := 1; ; attempt++ {
+		var start = time.Now()
+		resp, err := api.client.SendWithContext(ctx, req)
+		if api.tracer != nil {
+			api.tracer.trace(req, resp, err, attempt, start)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("humanitec api: %s %s: %w", req.Method, req.BaseURL, err)
+		}
+		if attempt > policy.MaxRetries || !isRetryable(req, resp.StatusCode, retryConflict) {
+			return resp, nil
+		}
+
+		delay, ok := retryAfter(resp)
+		if !ok {
+			delay = policy.backoff(attempt)
+		}
+		if !deadline.IsZero() && time.Now().Add(delay).After(deadline) {
+			return resp, nil
+		}
+
+		var timer = time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("humanitec api: %s %s: %w", req.Method, req.BaseURL, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sendgrid/rest"
+	"github.com/stretchr/testify/assert"
+
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+	"github.com/score-spec/score-humanitec/internal/testutil"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	var policy = RetryPolicy{InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt, expected := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		var delay = policy.backoff(attempt + 1)
+		assert.GreaterOrEqual(t, delay, expected/2)
+		assert.LessOrEqual(t, delay, expected)
+	}
+
+	assert.Equal(t, time.Duration(0), RetryPolicy{}.backoff(1))
+}
+
+func TestRetryAfter(t *testing.T) {
+	delay, ok := retryAfter(&rest.Response{Headers: map[string][]string{"Retry-After": {"3"}}})
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, delay)
+
+	delay, ok = retryAfter(&rest.Response{Headers: map[string][]string{"Retry-After": {"Wed, 21 Oct 2015 07:28:00 GMT"}}})
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), delay)
+
+	_, ok = retryAfter(&rest.Response{Headers: map[string][]string{"Retry-After": {"soon"}}})
+	assert.False(t, ok)
+
+	_, ok = retryAfter(&rest.Response{})
+	assert.False(t, ok)
+}
+
+func TestRetry(t *testing.T) {
+	const (
+		orgID    = "test_org"
+		appID    = "test-app"
+		apiToken = "qwe...rty"
+	)
+
+	var policy = RetryPolicy{MaxRetries: 2, InitialDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+	var tests = []struct {
+		Name             string
+		Method           string
+		Policy           RetryPolicy
+		StatusCode       []int
+		RetryAfter       string
+		ExpectedRequests int
+		ExpectedError    string
+	}{
+		{
+			Name:             "Should retry rate limited requests",
+			Method:           http.MethodGet,
+			Policy:           policy,
+			StatusCode:       []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusOK},
+			RetryAfter:       "0",
+			ExpectedRequests: 3,
+		},
+		{
+			Name:             "Should retry unavailable service for non-idempotent requests",
+			Method:           http.MethodPost,
+			Policy:           policy,
+			StatusCode:       []int{http.StatusServiceUnavailable, http.StatusOK},
+			ExpectedRequests: 2,
+		},
+		{
+			Name:             "Should retry gateway errors for idempotent requests",
+			Method:           http.MethodGet,
+			Policy:           policy,
+			StatusCode:       []int{http.StatusBadGateway, http.StatusGatewayTimeout, http.StatusOK},
+			ExpectedRequests: 3,
+		},
+		{
+			Name:             "Should not retry gateway errors for non-idempotent requests",
+			Method:           http.MethodPost,
+			Policy:           policy,
+			StatusCode:       []int{http.StatusBadGateway},
+			ExpectedRequests: 1,
+			ExpectedError:    "unexpected response status 502",
+		},
+		{
+			Name:             "Should not retry server errors",
+			Method:           http.MethodGet,
+			Policy:           policy,
+			StatusCode:       []int{http.StatusInternalServerError},
+			ExpectedRequests: 1,
+			ExpectedError:    "unexpected response status 500",
+		},
+		{
+			Name:             "Should stop after max retries",
+			Method:           http.MethodGet,
+			Policy:           policy,
+			StatusCode:       []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			ExpectedRequests: 3,
+			ExpectedError:    "unexpected response status 503",
+		},
+		{
+			Name:             "Should stop when the delay exceeds max duration",
+			Method:           http.MethodGet,
+			Policy:           RetryPolicy{MaxRetries: 2, MaxDuration: time.Second},
+			StatusCode:       []int{http.StatusServiceUnavailable},
+			RetryAfter:       "60",
+			ExpectedRequests: 1,
+			ExpectedError:    "unexpected response status 503",
+		},
+		{
+			Name:             "Should not retry if disabled",
+			Method:           http.MethodGet,
+			Policy:           RetryPolicy{},
+			StatusCode:       []int{http.StatusTooManyRequests},
+			ExpectedRequests: 1,
+			ExpectedError:    "unexpected response status 429",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			request := 0
+			fakeServer := httptest.NewServer(
+				http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						assert.Equal(t, tt.Method, r.Method)
+						if tt.RetryAfter != "" {
+							w.Header().Set("Retry-After", tt.RetryAfter)
+						}
+						w.WriteHeader(tt.StatusCode[request])
+						if r.Method == http.MethodGet {
+							w.Write([]byte(`[]`))
+						} else {
+							w.Write([]byte(`{}`))
+						}
+						request++
+					},
+				),
+			)
+			defer fakeServer.Close()
+
+			client, err := NewClient(fakeServer.URL, apiToken, fakeServer.Client(), WithRetryPolicy(tt.Policy))
+			assert.NoError(t, err)
+			if tt.Method == http.MethodGet {
+				_, err = client.ListResourceTypes(testutil.TestContext(), orgID)
+			} else {
+				_, err = client.CreateDelta(testutil.TestContext(), orgID, appID, &humanitec.CreateDeploymentDeltaRequest{})
+			}
+
+			if tt.ExpectedError != "" {
+				assert.ErrorContains(t, err, tt.ExpectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.ExpectedRequests, request)
+		})
+	}
+}
+
+func TestRetryContextCancel(t *testing.T) {
+	request := 0
+	fakeServer := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "60")
+				w.WriteHeader(http.StatusTooManyRequests)
+				request++
+			},
+		),
+	)
+	defer fakeServer.Close()
+
+	ctx, cancel := context.WithTimeout(testutil.TestContext(), 50*time.Millisecond)
+	defer cancel()
+
+	client, err := NewClient(fakeServer.URL, "qwe...rty", fakeServer.Client())
+	assert.NoError(t, err)
+	_, err = client.ListResourceTypes(ctx, "test_org")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, request)
+}
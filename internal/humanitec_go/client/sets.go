@@ -0,0 +1,75 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sendgrid/rest"
+
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+// GetSet gets the Deployment Set with the given setID.
+func (api *apiClient) GetSet(ctx context.Context, orgID, appID, setID string) (*humanitec.Set, error) {
+	apiPath := fmt.Sprintf("/orgs/%s/apps/%s/sets/%s", orgID, appID, setID)
+	req := rest.Request{
+		Method:  http.MethodGet,
+		BaseURL: api.baseUrl + apiPath,
+		Headers: map[string]string{
+			"Authorization":        "Bearer " + api.token,
+			"Accept":               "application/json",
+			"Humanitec-User-Agent": api.humanitecUserAgent,
+		},
+	}
+
+	resp, err := api.send(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		{
+			var res humanitec.Set
+			if err = json.Unmarshal([]byte(resp.Body), &res); err != nil {
+				return nil, fmt.Errorf("humanitec api: %s %s: parsing response: %w", req.Method, req.BaseURL, err)
+			}
+			return &res, nil
+		}
+
+	default:
+		return nil, resError(req, resp)
+	}
+}
+
+// DeployedSetGetter is the part of the Client used by GetDeployedSet.
+type DeployedSetGetter interface {
+	GetEnvironment(ctx context.Context, orgID, appID, envID string) (*humanitec.Environment, error)
+	GetSet(ctx context.Context, orgID, appID, setID string) (*humanitec.Set, error)
+}
+
+// GetDeployedSet gets the Deployment Set of the last deployment in the environment.
+// Returns an empty set if the environment has not been deployed yet.
+func GetDeployedSet(ctx context.Context, client DeployedSetGetter, orgID, appID, envID string) (*humanitec.Set, error) {
+	env, err := client.GetEnvironment(ctx, orgID, appID, envID)
+	if err != nil {
+		return nil, err
+	}
+	if env.LastDeploy == nil || env.LastDeploy.SetID == "" {
+		return &humanitec.Set{
+			Modules: map[string]map[string]interface{}{},
+			Shared:  map[string]interface{}{},
+		}, nil
+	}
+
+	return client.GetSet(ctx, orgID, appID, env.LastDeploy.SetID)
+}
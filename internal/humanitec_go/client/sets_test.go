@@ -0,0 +1,169 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+	"github.com/score-spec/score-humanitec/internal/testutil"
+)
+
+func TestGetSet(t *testing.T) {
+	const (
+		orgID    = "test_org"
+		appID    = "test-app"
+		setID    = "test-set"
+		apiToken = "qwe...rty"
+	)
+
+	var tests = []struct {
+		Name           string
+		ApiUrl         string
+		StatusCode     int
+		Response       []byte
+		ExpectedResult *humanitec.Set
+		ExpectedError  error
+	}{
+		// Success Path
+		//
+		{
+			Name:       "Should return the Deployment Set",
+			StatusCode: http.StatusOK,
+			Response: []byte(`{
+				"id": "test-set",
+				"modules": { "backend": { "profile": "humanitec/default-module" } },
+				"shared": { "dns": { "type": "dns" } },
+				"version": 1
+			}`),
+			ExpectedResult: &humanitec.Set{
+				ID: setID,
+				Modules: map[string]map[string]interface{}{
+					"backend": {"profile": "humanitec/default-module"},
+				},
+				Shared: map[string]interface{}{
+					"dns": map[string]interface{}{"type": "dns"},
+				},
+				Version: 1,
+			},
+		},
+		// Errors Handling
+		//
+		{
+			Name:          "Should handle request errors",
+			ApiUrl:        "bad URL",
+			ExpectedError: errors.New("unsupported protocol scheme"),
+		},
+		{
+			Name:          "Should handle API errors",
+			StatusCode:    http.StatusNotFound,
+			Response:      []byte(`error details`),
+			ExpectedError: errors.New("unexpected response status 404 - Not Found\nerror details"),
+		},
+		{
+			Name:          "Should handle response parsing errors",
+			StatusCode:    http.StatusOK,
+			Response:      []byte(`{NOT A VALID JSON}`),
+			ExpectedError: errors.New("parsing response"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			fakeServer := httptest.NewServer(
+				http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						switch r.URL.Path {
+						case fmt.Sprintf("/orgs/%s/apps/%s/sets/%s", orgID, appID, setID):
+							if r.Method != http.MethodGet {
+								w.WriteHeader(http.StatusMethodNotAllowed)
+								return
+							}
+							assert.Equal(t, []string{"Bearer " + apiToken}, r.Header["Authorization"])
+							assert.Equal(t, []string{"application/json"}, r.Header["Accept"])
+							assert.Equal(t, []string{"app score-humanitec/0.0.0; sdk score-humanitec/0.0.0"}, r.Header["Humanitec-User-Agent"])
+							w.WriteHeader(tt.StatusCode)
+							if len(tt.Response) > 0 {
+								w.Write(tt.Response)
+							}
+							return
+						}
+						w.WriteHeader(http.StatusNotFound)
+					},
+				),
+			)
+			defer fakeServer.Close()
+
+			if tt.ApiUrl == "" {
+				tt.ApiUrl = fakeServer.URL
+			}
+
+			client, err := NewClient(tt.ApiUrl, apiToken, fakeServer.Client())
+			assert.NoError(t, err)
+
+			res, err := client.GetSet(testutil.TestContext(), orgID, appID, setID)
+
+			if tt.ExpectedError != nil {
+				// On Error
+				assert.ErrorContains(t, err, tt.ExpectedError.Error())
+			} else {
+				// On Success
+				assert.NoError(t, err)
+				assert.Equal(t, tt.ExpectedResult, res)
+			}
+		})
+	}
+}
+
+func TestGetDeployedSet(t *testing.T) {
+	const (
+		orgID    = "test_org"
+		appID    = "test-app"
+		apiToken = "qwe...rty"
+	)
+
+	fakeServer := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case fmt.Sprintf("/orgs/%s/apps/%s/envs/deployed", orgID, appID):
+					w.Write([]byte(`{ "id": "deployed", "last_deploy": { "id": "qwe...rty", "set_id": "test-set" } }`))
+				case fmt.Sprintf("/orgs/%s/apps/%s/envs/new", orgID, appID):
+					w.Write([]byte(`{ "id": "new" }`))
+				case fmt.Sprintf("/orgs/%s/apps/%s/sets/test-set", orgID, appID):
+					w.Write([]byte(`{ "id": "test-set", "modules": { "backend": {} }, "shared": {} }`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			},
+		),
+	)
+	defer fakeServer.Close()
+
+	client, err := NewClient(fakeServer.URL, apiToken, fakeServer.Client())
+	assert.NoError(t, err)
+
+	res, err := GetDeployedSet(testutil.TestContext(), client, orgID, appID, "deployed")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-set", res.ID)
+	assert.Contains(t, res.Modules, "backend")
+
+	res, err = GetDeployedSet(testutil.TestContext(), client, orgID, appID, "new")
+	assert.NoError(t, err)
+	assert.Empty(t, res.Modules)
+	assert.Empty(t, res.Shared)
+
+	_, err = GetDeployedSet(testutil.TestContext(), client, orgID, appID, "missing")
+	assert.ErrorContains(t, err, "unexpected response status 404")
+}
@@ -0,0 +1,215 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sendgrid/rest"
+)
+
+const redacted = "<redacted>"
+
+// DefaultSecretPatterns are the variable names and file paths patterns which values are redacted in traces.
+var DefaultSecretPatterns = []string{
+	`(?i)passw(or)?d`,
+	`(?i)secret`,
+	`(?i)token`,
+	`(?i)api[-_]?key`,
+	`(?i)private[-_]?key`,
+	`(?i)credential`,
+}
+
+// Tracer logs the API requests and responses as JSON lines.
+//
+// The 'Authorization' header is always redacted. The values of the container variables and the contents
+// of the container files are redacted if the variable name or the file path matches any of the secret patterns.
+type Tracer struct {
+	mu             sync.Mutex
+	out            io.Writer
+	secretPatterns []*regexp.Regexp
+}
+
+// TraceRecord is a single API call attempt in the trace.
+type TraceRecord struct {
+	Time            time.Time         `json:"time"`
+	Attempt         int               `json:"attempt"`
+	Method          string            `json:"method"`
+	URL             string            `json:"url"`
+	RequestHeaders  map[string]string `json:"request_headers,omitempty"`
+	RequestBody     interface{}       `json:"request_body,omitempty"`
+	Status          int               `json:"status,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	ResponseBody    interface{}       `json:"response_body,omitempty"`
+	DurationMs      float64           `json:"duration_ms"`
+	Error           string            `json:"error,omitempty"`
+}
+
+// NewTracer creates a new Tracer writing into out.
+func NewTracer(out io.Writer, secretPatterns []string) (*Tracer, error) {
+	var tracer = &Tracer{out: out}
+	for _, pattern := range secretPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret pattern '%s': %w", pattern, err)
+		}
+		tracer.secretPatterns = append(tracer.secretPatterns, re)
+	}
+	return tracer, nil
+}
+
+// WithTracer enables tracing of all the API calls.
+func WithTracer(tracer *Tracer) ClientOption {
+	return func(api *apiClient) {
+		api.tracer = tracer
+	}
+}
+
+// trace writes the record for the API call attempt.
+func (t *Tracer) trace(req rest.Request, resp *rest.Response, err error, attempt int, start time.Time) {
+	var record = TraceRecord{
+		Time:           start.UTC(),
+		Attempt:        attempt,
+		Method:         string(req.Method),
+		URL:            req.BaseURL,
+		RequestHeaders: t.redactHeaders(req.Headers),
+		RequestBody:    t.redactBody([]byte(req.Body)),
+		DurationMs:     float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if len(req.QueryParams) > 0 {
+		record.URL = rest.AddQueryParameters(req.BaseURL, req.QueryParams)
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if resp != nil {
+		record.Status = resp.StatusCode
+		record.ResponseBody = t.redactBody([]byte(resp.Body))
+		record.ResponseHeaders = make(map[string]string, len(resp.Headers))
+		for key, values := range resp.Headers {
+			record.ResponseHeaders[key] = strings.Join(values, ", ")
+		}
+	}
+
+	var buf bytes.Buffer
+	var enc = json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(&record); err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.out.Write(buf.Bytes())
+}
+
+func (t *Tracer) redactHeaders(headers map[string]string) map[string]string {
+	var res = make(map[string]string, len(headers))
+	for key, val := range headers {
+		if strings.EqualFold(key, "Authorization") {
+			if scheme, _, found := strings.Cut(val, " "); found {
+				val = scheme + " " + redacted
+			} else {
+				val = redacted
+			}
+		}
+		res[key] = val
+	}
+	return res
+}
+
+// redactBody returns the redacted JSON body, or the raw body as a string if it is not a valid JSON.
+func (t *Tracer) redactBody(body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return string(body)
+	}
+	return t.redact(data, "")
+}
+
+func (t *Tracer) redact(data interface{}, parentKey string) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		// JSON Patch operations, e.g. {"op": "add", "path": "/spec/containers/main/variables/DB_PASSWORD", "value": "..."}
+		if path, ok := v["path"].(string); ok {
+			if val, hasValue := v["value"]; hasValue {
+				if t.isSecretPath(path) {
+					v["value"] = redacted
+					return v
+				}
+				// The operation value is addressed by the last path segment, e.g. '/spec/containers/main/variables'
+				var segments = splitPointer(path)
+				v["value"] = t.redact(val, segments[len(segments)-1])
+				return v
+			}
+		}
+		for key, val := range v {
+			switch {
+			case parentKey == "variables" && t.isSecret(key):
+				v[key] = redacted
+			case parentKey == "files" && t.isSecret(key):
+				if file, ok := val.(map[string]interface{}); ok {
+					if _, hasValue := file["value"]; hasValue {
+						file["value"] = redacted
+					}
+				} else {
+					v[key] = redacted
+				}
+			default:
+				v[key] = t.redact(val, key)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = t.redact(val, parentKey)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// isSecretPath checks whether the JSON pointer addresses a secret variable or file.
+func (t *Tracer) isSecretPath(path string) bool {
+	var segments = splitPointer(path)
+	for i := 0; i+1 < len(segments); i++ {
+		if (segments[i] == "variables" || segments[i] == "files") && t.isSecret(segments[i+1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Tracer) isSecret(name string) bool {
+	for _, re := range t.secretPatterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitPointer splits the JSON pointer into unescaped segments.
+func splitPointer(pointer string) []string {
+	var segments = strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+	}
+	return segments
+}
@@ -0,0 +1,143 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	humanitec "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+	"github.com/score-spec/score-humanitec/internal/testutil"
+)
+
+func TestTracerRedact(t *testing.T) {
+	tracer, err := NewTracer(nil, DefaultSecretPatterns)
+	assert.NoError(t, err)
+
+	var tests = []struct {
+		Name     string
+		Body     string
+		Expected interface{}
+	}{
+		{
+			Name:     "Should keep non-JSON body",
+			Body:     `not a json`,
+			Expected: "not a json",
+		},
+		{
+			Name: "Should redact secret variables and files",
+			Body: `{"containers": {"main": {
+				"variables": {"DB_PASSWORD": "p@ss", "PORT": "80"},
+				"files": {"/run/secrets/token": {"mode": "0600", "value": "s3cr3t"}, "/etc/app.conf": {"value": "debug=true"}}
+			}}}`,
+			Expected: map[string]interface{}{"containers": map[string]interface{}{"main": map[string]interface{}{
+				"variables": map[string]interface{}{"DB_PASSWORD": redacted, "PORT": "80"},
+				"files": map[string]interface{}{
+					"/run/secrets/token": map[string]interface{}{"mode": "0600", "value": redacted},
+					"/etc/app.conf":      map[string]interface{}{"value": "debug=true"},
+				},
+			}}},
+		},
+		{
+			Name: "Should redact JSON Patch operations",
+			Body: `[
+				{"op": "add", "path": "/spec/containers/main/variables/API_KEY", "value": "k3y"},
+				{"op": "add", "path": "/spec/containers/main/variables", "value": {"SECRET": "s3cr3t", "DEBUG": "true"}},
+				{"op": "remove", "path": "/spec/containers/main/variables/TOKEN"}
+			]`,
+			Expected: []interface{}{
+				map[string]interface{}{"op": "add", "path": "/spec/containers/main/variables/API_KEY", "value": redacted},
+				map[string]interface{}{"op": "add", "path": "/spec/containers/main/variables", "value": map[string]interface{}{"SECRET": redacted, "DEBUG": "true"}},
+				map[string]interface{}{"op": "remove", "path": "/spec/containers/main/variables/TOKEN"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, tracer.redactBody([]byte(tt.Body)))
+		})
+	}
+
+	_, err = NewTracer(nil, []string{`(`})
+	assert.ErrorContains(t, err, "invalid secret pattern '('")
+}
+
+func TestTrace(t *testing.T) {
+	const (
+		orgID    = "test_org"
+		appID    = "test-app"
+		apiToken = "qwe...rty"
+	)
+
+	request := 0
+	fakeServer := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				request++
+				if request == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id": "qwe...rty"}`))
+			},
+		),
+	)
+	defer fakeServer.Close()
+
+	var out bytes.Buffer
+	tracer, err := NewTracer(&out, DefaultSecretPatterns)
+	assert.NoError(t, err)
+
+	client, err := NewClient(fakeServer.URL, apiToken, fakeServer.Client(),
+		WithTracer(tracer), WithRetryPolicy(RetryPolicy{MaxRetries: 1, InitialDelay: time.Millisecond}))
+	assert.NoError(t, err)
+	_, err = client.CreateDelta(testutil.TestContext(), orgID, appID, &humanitec.CreateDeploymentDeltaRequest{
+		Modules: humanitec.ModuleDeltas{
+			Add: map[string]map[string]interface{}{
+				"test": {"spec": map[string]interface{}{"containers": map[string]interface{}{"main": map[string]interface{}{
+					"variables": map[string]interface{}{"DB_PASSWORD": "p@ss"},
+				}}}},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	var records []TraceRecord
+	var scanner = bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var record TraceRecord
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	assert.Len(t, records, 2)
+
+	assert.Equal(t, 1, records[0].Attempt)
+	assert.Equal(t, http.StatusServiceUnavailable, records[0].Status)
+	assert.Equal(t, 2, records[1].Attempt)
+	assert.Equal(t, http.StatusCreated, records[1].Status)
+
+	for _, record := range records {
+		assert.Equal(t, http.MethodPost, record.Method)
+		assert.Equal(t, fakeServer.URL+"/orgs/test_org/apps/test-app/deltas", record.URL)
+		assert.Equal(t, "Bearer <redacted>", record.RequestHeaders["Authorization"])
+	}
+	assert.Equal(t, map[string]interface{}{"id": "qwe...rty"}, records[1].ResponseBody)
+	assert.Equal(t, "application/json", records[1].ResponseHeaders["Content-Type"])
+	assert.NotContains(t, out.String(), apiToken)
+	assert.NotContains(t, out.String(), "p@ss")
+}
@@ -0,0 +1,89 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// TransportOptions configures the HTTP client used to access the Humanitec API.
+type TransportOptions struct {
+	// Timeout limits the time for a single API request, including reading the response body. Zero means no timeout.
+	Timeout time.Duration
+
+	// CAFile is the path to a PEM bundle with additional trusted certificate authorities.
+	CAFile string
+
+	// ClientCertFile and ClientKeyFile are the paths to a PEM client certificate and private key for mutual TLS.
+	ClientCertFile string
+	ClientKeyFile  string
+
+	// InsecureSkipVerify disables the server certificate verification.
+	InsecureSkipVerify bool
+
+	// ProxyURL is the HTTP proxy to use. The standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	// environment variables are used if empty.
+	ProxyURL string
+}
+
+// NewHTTPClient builds the HTTP client to pass into NewClient.
+func NewHTTPClient(opts TransportOptions) (*http.Client, error) {
+	var transport = http.DefaultTransport.(*http.Transport).Clone()
+
+	var tlsConfig = &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("reading CA file '%s': no PEM certificates found", opts.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if opts.ClientCertFile != "" || opts.ClientKeyFile != "" {
+		if opts.ClientCertFile == "" || opts.ClientKeyFile == "" {
+			return nil, fmt.Errorf("both client certificate and key files are required")
+		}
+		cert, err := tls.LoadX509KeyPair(opts.ClientCertFile, opts.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if opts.ProxyURL != "" {
+		proxy, err := url.Parse(opts.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing proxy URL: %w", err)
+		}
+		if proxy.Scheme == "" || proxy.Host == "" {
+			return nil, fmt.Errorf("parsing proxy URL: '%s' is not an absolute URL", opts.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   opts.Timeout,
+	}, nil
+}
@@ -0,0 +1,207 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writePEM writes the PEM encoded block into a temporary file and returns the file path.
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	var path = filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+	return path
+}
+
+// generateClientCert creates a self-signed client certificate and returns the certificate and the PEM file paths.
+func generateClientCert(t *testing.T) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	var template = &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "score-humanitec"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	return cert, writePEM(t, "client.crt", "CERTIFICATE", der), writePEM(t, "client.key", "EC PRIVATE KEY", keyDer)
+}
+
+func TestNewHTTPClient_tls(t *testing.T) {
+	fakeServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer fakeServer.Close()
+
+	var caFile = writePEM(t, "ca.crt", "CERTIFICATE", fakeServer.Certificate().Raw)
+
+	var tests = []struct {
+		Name          string
+		Options       TransportOptions
+		ExpectedError string
+	}{
+		{
+			Name:          "Should reject unknown certificate authority",
+			Options:       TransportOptions{},
+			ExpectedError: "certificate",
+		},
+		{
+			Name:    "Should trust custom certificate authority",
+			Options: TransportOptions{CAFile: caFile},
+		},
+		{
+			Name:    "Should skip certificate verification",
+			Options: TransportOptions{InsecureSkipVerify: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			httpClient, err := NewHTTPClient(tt.Options)
+			assert.NoError(t, err)
+
+			resp, err := httpClient.Get(fakeServer.URL)
+			if tt.ExpectedError != "" {
+				assert.ErrorContains(t, err, tt.ExpectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				resp.Body.Close()
+			}
+		})
+	}
+}
+
+func TestNewHTTPClient_mtls(t *testing.T) {
+	clientCert, certFile, keyFile := generateClientCert(t)
+
+	var clientCAs = x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	fakeServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "score-humanitec", r.TLS.PeerCertificates[0].Subject.CommonName)
+		w.WriteHeader(http.StatusOK)
+	}))
+	fakeServer.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	fakeServer.StartTLS()
+	defer fakeServer.Close()
+
+	var caFile = writePEM(t, "ca.crt", "CERTIFICATE", fakeServer.Certificate().Raw)
+
+	// Without client certificate
+	httpClient, err := NewHTTPClient(TransportOptions{CAFile: caFile})
+	assert.NoError(t, err)
+	_, err = httpClient.Get(fakeServer.URL)
+	assert.Error(t, err)
+
+	// With client certificate
+	httpClient, err = NewHTTPClient(TransportOptions{CAFile: caFile, ClientCertFile: certFile, ClientKeyFile: keyFile})
+	assert.NoError(t, err)
+	resp, err := httpClient.Get(fakeServer.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func TestNewHTTPClient_proxy(t *testing.T) {
+	var proxied string
+	fakeProxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer fakeProxy.Close()
+
+	httpClient, err := NewHTTPClient(TransportOptions{ProxyURL: fakeProxy.URL})
+	assert.NoError(t, err)
+	resp, err := httpClient.Get("http://api.humanitec.invalid/orgs/test_org")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "http://api.humanitec.invalid/orgs/test_org", proxied)
+}
+
+func TestNewHTTPClient_timeout(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer fakeServer.Close()
+
+	httpClient, err := NewHTTPClient(TransportOptions{Timeout: 50 * time.Millisecond})
+	assert.NoError(t, err)
+	_, err = httpClient.Get(fakeServer.URL)
+	assert.ErrorContains(t, err, "Client.Timeout exceeded")
+}
+
+func TestNewHTTPClient_errors(t *testing.T) {
+	var invalidFile = filepath.Join(t.TempDir(), "invalid.pem")
+	assert.NoError(t, os.WriteFile(invalidFile, []byte("<NOT A PEM>"), 0600))
+
+	var tests = []struct {
+		Name          string
+		Options       TransportOptions
+		ExpectedError string
+	}{
+		{
+			Name:          "Should fail on missing CA file",
+			Options:       TransportOptions{CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+			ExpectedError: "reading CA file",
+		},
+		{
+			Name:          "Should fail on invalid CA file",
+			Options:       TransportOptions{CAFile: invalidFile},
+			ExpectedError: "no PEM certificates found",
+		},
+		{
+			Name:          "Should require both client certificate and key",
+			Options:       TransportOptions{ClientCertFile: invalidFile},
+			ExpectedError: "both client certificate and key files are required",
+		},
+		{
+			Name:          "Should fail on invalid client certificate",
+			Options:       TransportOptions{ClientCertFile: invalidFile, ClientKeyFile: invalidFile},
+			ExpectedError: "loading client certificate",
+		},
+		{
+			Name:          "Should fail on relative proxy URL",
+			Options:       TransportOptions{ProxyURL: "proxy.local:3128"},
+			ExpectedError: "parsing proxy URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := NewHTTPClient(tt.Options)
+			assert.ErrorContains(t, err, tt.ExpectedError)
+		})
+	}
+}
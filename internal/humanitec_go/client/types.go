@@ -20,12 +20,28 @@ type Client interface {
 	//
 	ListResourceTypes(ctx context.Context, orgID string) ([]humanitec.ResourceType, error)
 
+	// Environments
+	//
+	GetEnvironment(ctx context.Context, orgID, appID, envID string) (*humanitec.Environment, error)
+	CreateEnvironment(ctx context.Context, orgID, appID string, env *humanitec.CreateEnvironmentRequest) (*humanitec.Environment, error)
+	DeleteEnvironment(ctx context.Context, orgID, appID, envID string) error
+
+	// Deployment Sets
+	//
+	GetSet(ctx context.Context, orgID, appID, setID string) (*humanitec.Set, error)
+
 	// Deployment Deltas
 	//
 	CreateDelta(ctx context.Context, orgID, appID string, delta *humanitec.CreateDeploymentDeltaRequest) (*humanitec.DeploymentDelta, error)
 	UpdateDelta(ctx context.Context, orgID string, appID string, deltaID string, deltas []*humanitec.UpdateDeploymentDeltaRequest) (*humanitec.DeploymentDelta, error)
+	GetDelta(ctx context.Context, orgID, appID, deltaID string) (*humanitec.DeploymentDelta, error)
+	ListDeltas(ctx context.Context, orgID, appID, envID string, archived bool) ([]humanitec.DeploymentDelta, error)
+	ArchiveDelta(ctx context.Context, orgID, appID, deltaID string) error
+	DeleteDelta(ctx context.Context, orgID, appID, deltaID string) error
 
 	// Deployments
 	//
 	StartDeployment(ctx context.Context, orgID, appID, envID string, retry bool, deployment *humanitec.StartDeploymentRequest) (*humanitec.Deployment, error)
+	GetDeployment(ctx context.Context, orgID, appID, envID, deploymentID string) (*humanitec.Deployment, error)
+	ListDeployments(ctx context.Context, orgID, appID, envID string) ([]humanitec.Deployment, error)
 }
@@ -9,8 +9,17 @@ package types
 
 import "time"
 
+const (
+	DeploymentStatusPending    = "pending"
+	DeploymentStatusInProgress = "in progress"
+	DeploymentStatusSucceeded  = "succeeded"
+	DeploymentStatusFailed     = "failed"
+)
+
 type StartDeploymentRequest struct {
-	DeltaID string `json:"delta_id"`
+	DeltaID string `json:"delta_id,omitempty"`
+	// SetID deploys the given Deployment Set as is, e.g. to redeploy a previous Deployment.
+	SetID   string `json:"set_id,omitempty"`
 	Comment string `json:"comment"`
 }
 
@@ -20,6 +29,7 @@ type Deployment struct {
 
 	FromID  string `json:"from_id"`
 	DeltaID string `json:"delta_id"`
+	SetID   string `json:"set_id,omitempty"`
 	Comment string `json:"comment"`
 
 	Status          string    `json:"status"`
@@ -28,3 +38,8 @@ type Deployment struct {
 	CreatedBy string    `json:"created_by"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// IsCompleted returns true if the deployment has reached a terminal state.
+func (d *Deployment) IsCompleted() bool {
+	return d.Status == DeploymentStatusSucceeded || d.Status == DeploymentStatusFailed
+}
@@ -0,0 +1,30 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package types
+
+import "time"
+
+type CreateEnvironmentRequest struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
+
+	// FromDeployID is the ID of the Deployment to clone into the new Environment (optional).
+	FromDeployID string `json:"from_deploy_id,omitempty"`
+}
+
+type Environment struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+
+	LastDeploy *Deployment `json:"last_deploy,omitempty"`
+
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
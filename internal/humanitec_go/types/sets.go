@@ -0,0 +1,15 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package types
+
+type Set struct {
+	ID      string                            `json:"id,omitempty"`
+	Modules map[string]map[string]interface{} `json:"modules"`
+	Shared  map[string]interface{}            `json:"shared"`
+	Version int                               `json:"version,omitempty"`
+}
@@ -0,0 +1,110 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+
+// Package convert is the public API to convert SCORE specifications into Humanitec deployment deltas.
+//
+// Workloads are loaded from memory with Load, without accessing the file system, except for the containers files
+// sources, which are read with the Source.ReadFile reader (e.g. ReadFS), or os.ReadFile if not set.
+// The progress messages are written to the Source.Logger logger, and discarded if not set, while the conversion
+// warnings are returned by Convert.
+package convert
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/score-spec/score-humanitec/internal/humanitec"
+	types "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+// DefaultName is the deployment delta name used unless configured otherwise.
+const DefaultName = "Auto-deployment (SCORE)"
+
+type (
+	// Source is the in-memory content of a SCORE workload specification and its customizations.
+	Source = humanitec.LoadOptions
+	// FileReader reads the content of the file at the path, e.g. a container file source.
+	FileReader = humanitec.FileReader
+	// Workload is a loaded SCORE workload specification, ready to be converted (see Load).
+	Workload = humanitec.WorkloadSource
+
+	// ValidationError is returned when the workloads resources do not match the organization resource types.
+	ValidationError = humanitec.ValidationError
+	// UnresolvedReferencesError is returned in strict mode when some '${...}' references can not be resolved.
+	UnresolvedReferencesError = humanitec.UnresolvedReferencesError
+	// Warning is a non fatal problem found while converting a workload, e.g. an unresolved reference in non-strict mode.
+	Warning = humanitec.Warning
+)
+
+// Options are the conversion settings.
+type Options struct {
+	// Name is the deployment delta name. Defaults to DefaultName.
+	Name string
+	// EnvID is the target environment ID.
+	EnvID string
+	// WorkloadSourceURL is the URL of the file managing the workloads, added to the modules annotations (optional).
+	WorkloadSourceURL string
+	// Strict fails the conversion if any '${...}' reference can not be resolved.
+	Strict bool
+}
+
+// Load parses, customizes and validates the SCORE specification.
+func Load(src Source) (*Workload, error) {
+	return humanitec.LoadWorkload(src)
+}
+
+// ReadFS returns a FileReader reading the files from the file system, e.g. an embed.FS.
+// The paths are cleaned and made relative to the file system root.
+func ReadFS(fsys fs.FS) FileReader {
+	return func(path string) ([]byte, error) {
+		var name = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "/")
+		if name == "" {
+			name = "."
+		}
+		return fs.ReadFile(fsys, name)
+	}
+}
+
+// Convert converts the workloads into a single Humanitec deployment delta.
+// Shared resources declared by more than one workload are added only once.
+// Returns the deployment delta along with the conversion warnings.
+func Convert(opts Options, workloads ...*Workload) (*types.CreateDeploymentDeltaRequest, []Warning, error) {
+	if opts.EnvID == "" {
+		return nil, nil, fmt.Errorf("environment ID is required")
+	}
+	if len(workloads) == 0 {
+		return nil, nil, fmt.Errorf("no workloads to convert")
+	}
+	var name = opts.Name
+	if name == "" {
+		name = DefaultName
+	}
+
+	var sources = make([]humanitec.WorkloadSource, 0, len(workloads))
+	for _, w := range workloads {
+		sources = append(sources, *w)
+	}
+	return humanitec.ConvertSpecs(name, opts.EnvID, opts.WorkloadSourceURL, sources, opts.Strict)
+}
+
+// ValidateResources checks the workloads resources against the organization resource types.
+// Returns a ValidationError describing all the problems found.
+func ValidateResources(resTypes []types.ResourceType, workloads ...*Workload) error {
+	var sources = make([]humanitec.WorkloadSource, 0, len(workloads))
+	for _, w := range workloads {
+		sources = append(sources, *w)
+	}
+	return humanitec.ValidateResources(sources, resTypes)
+}
+
+// ValidateDelta validates the deployment delta against the embedded Humanitec JSON schema.
+func ValidateDelta(delta *types.CreateDeploymentDeltaRequest) error {
+	return humanitec.ValidateDelta(delta)
+}
@@ -0,0 +1,113 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package convert
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	types "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+func TestConvert(t *testing.T) {
+	var fsys = fstest.MapFS{
+		"backend/score.yaml": &fstest.MapFile{Data: []byte(`apiVersion: score.dev/v1b1
+metadata:
+  name: backend
+containers:
+  main:
+    image: .
+    files:
+      - target: /etc/backend/config.yaml
+        source: config.yaml
+    variables:
+      DB_HOST: ${resources.db.host}
+resources:
+  db:
+    type: postgres
+`)},
+		"backend/config.yaml": &fstest.MapFile{Data: []byte(`host: ${resources.db.host}`)},
+	}
+
+	spec, err := ReadFS(fsys)("backend/score.yaml")
+	assert.NoError(t, err)
+	workload, err := Load(Source{
+		Spec:     spec,
+		Image:    "backend:1.0",
+		BaseDir:  "backend",
+		ReadFile: ReadFS(fsys),
+	})
+	assert.NoError(t, err)
+
+	delta, warnings, err := Convert(Options{EnvID: "development", Strict: true}, workload)
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, types.DeltaMetadata{Name: DefaultName, EnvID: "development"}, delta.Metadata)
+	var container = delta.Modules.Add["backend"]["spec"].(map[string]interface{})["containers"].(map[string]interface{})["main"].(map[string]interface{})
+	assert.Equal(t, "backend:1.0", container["image"])
+	assert.Equal(t, map[string]interface{}{"DB_HOST": "${externals.db.host}"}, container["variables"])
+	assert.Equal(t, map[string]interface{}{
+		"/etc/backend/config.yaml": map[string]interface{}{"mode": "", "value": "host: ${externals.db.host}"},
+	}, container["files"])
+	assert.NoError(t, ValidateDelta(delta))
+
+	// Resources validation
+	err = ValidateResources([]types.ResourceType{{Type: "redis"}}, workload)
+	var verr *ValidationError
+	assert.True(t, errors.As(err, &verr))
+
+	// Missing file sources
+	workload.ReadFile = ReadFS(fstest.MapFS{})
+	_, _, err = Convert(Options{EnvID: "development"}, workload)
+	assert.ErrorContains(t, err, "reading 'backend/config.yaml'")
+}
+
+func TestConvert_errors(t *testing.T) {
+	workload, err := Load(Source{Spec: []byte(`apiVersion: score.dev/v1b1
+metadata:
+  name: backend
+containers:
+  main:
+    image: busybox
+    variables:
+      DB_HOST: ${resources.db.host}
+`)})
+	assert.NoError(t, err)
+
+	_, _, err = Convert(Options{}, workload)
+	assert.EqualError(t, err, "environment ID is required")
+
+	_, _, err = Convert(Options{EnvID: "development"})
+	assert.EqualError(t, err, "no workloads to convert")
+
+	_, warnings, err := Convert(Options{EnvID: "development"}, workload)
+	assert.NoError(t, err)
+	assert.Equal(t, []Warning{{Workload: "backend", Message: "Can not resolve '${resources.db.host}' in containers.main.variables.DB_HOST."}}, warnings)
+
+	_, _, err = Convert(Options{EnvID: "development", Strict: true}, workload)
+	var uerr *UnresolvedReferencesError
+	assert.True(t, errors.As(err, &uerr))
+
+	_, err = Load(Source{Spec: []byte(`{NOT A VALID YAML`)})
+	assert.Error(t, err)
+}
+
+func TestLoad_logger(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := Load(Source{
+		Spec:   []byte("apiVersion: score.dev/v1b1\nmetadata:\n  name: backend\ncontainers:\n  main:\n    image: busybox\n"),
+		Logger: log.New(&buf, "", 0),
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "Parsing SCORE spec...")
+}
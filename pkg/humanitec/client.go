@@ -0,0 +1,158 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+
+// Package humanitec is the public Humanitec API client used by score-humanitec.
+//
+// The ClientV1 interface is stable: its methods are never changed nor removed, and new API calls are only added
+// to new interface versions, so that applications can safely implement it (e.g. for testing).
+package humanitec
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/score-spec/score-humanitec/internal/humanitec_go/client"
+)
+
+// DefaultApiUrl is the Humanitec API endpoint used unless configured otherwise.
+const DefaultApiUrl = "https://api.humanitec.io"
+
+// ClientV1 is the version 1 of the Humanitec API client interface.
+type ClientV1 interface {
+
+	// Resources
+	//
+	ListResourceTypes(ctx context.Context, orgID string) ([]ResourceType, error)
+
+	// Environments
+	//
+	GetEnvironment(ctx context.Context, orgID, appID, envID string) (*Environment, error)
+	CreateEnvironment(ctx context.Context, orgID, appID string, env *CreateEnvironmentRequest) (*Environment, error)
+	DeleteEnvironment(ctx context.Context, orgID, appID, envID string) error
+
+	// Deployment Sets
+	//
+	GetSet(ctx context.Context, orgID, appID, setID string) (*Set, error)
+
+	// Deployment Deltas
+	//
+	CreateDelta(ctx context.Context, orgID, appID string, delta *CreateDeploymentDeltaRequest) (*DeploymentDelta, error)
+	UpdateDelta(ctx context.Context, orgID string, appID string, deltaID string, deltas []*UpdateDeploymentDeltaRequest) (*DeploymentDelta, error)
+	GetDelta(ctx context.Context, orgID, appID, deltaID string) (*DeploymentDelta, error)
+	ListDeltas(ctx context.Context, orgID, appID, envID string, archived bool) ([]DeploymentDelta, error)
+	ArchiveDelta(ctx context.Context, orgID, appID, deltaID string) error
+	DeleteDelta(ctx context.Context, orgID, appID, deltaID string) error
+
+	// Deployments
+	//
+	StartDeployment(ctx context.Context, orgID, appID, envID string, retry bool, deployment *StartDeploymentRequest) (*Deployment, error)
+	GetDeployment(ctx context.Context, orgID, appID, envID, deploymentID string) (*Deployment, error)
+	ListDeployments(ctx context.Context, orgID, appID, envID string) ([]Deployment, error)
+}
+
+type (
+	// RetryPolicy controls how transient API errors are retried (see DefaultRetryPolicy).
+	RetryPolicy = client.RetryPolicy
+	// TransportOptions are the HTTP transport settings (see NewHTTPClient).
+	TransportOptions = client.TransportOptions
+	// Tracer records the API requests and responses, with the secrets redacted (see NewTracer).
+	Tracer = client.Tracer
+	// APIError is the error returned for unexpected API responses.
+	APIError = client.APIError
+)
+
+// ClientOptions are the Humanitec API client settings.
+type ClientOptions struct {
+	// ApiUrl is the Humanitec API endpoint. Defaults to DefaultApiUrl.
+	ApiUrl string
+	// Token is the Humanitec API authentication token.
+	Token string
+
+	// HTTPClient is the HTTP client to use. Defaults to a client created from the Transport options.
+	HTTPClient *http.Client
+	// Transport are the HTTP transport settings, ignored if the HTTPClient is set.
+	Transport TransportOptions
+
+	// RetryPolicy is the policy for retrying transient API errors. Defaults to DefaultRetryPolicy.
+	RetryPolicy *RetryPolicy
+	// Tracer records the API calls, if set.
+	Tracer *Tracer
+}
+
+// NewClient constructs a new Humanitec API client.
+func NewClient(opts ClientOptions) (ClientV1, error) {
+	var url = opts.ApiUrl
+	if url == "" {
+		url = DefaultApiUrl
+	}
+
+	var httpClient = opts.HTTPClient
+	if httpClient == nil {
+		var err error
+		if httpClient, err = client.NewHTTPClient(opts.Transport); err != nil {
+			return nil, err
+		}
+	}
+
+	var clientOpts []client.ClientOption
+	if opts.RetryPolicy != nil {
+		clientOpts = append(clientOpts, client.WithRetryPolicy(*opts.RetryPolicy))
+	}
+	if opts.Tracer != nil {
+		clientOpts = append(clientOpts, client.WithTracer(opts.Tracer))
+	}
+
+	return client.NewClient(url, opts.Token, httpClient, clientOpts...)
+}
+
+// DefaultRetryPolicy returns the default policy for retrying transient API errors.
+func DefaultRetryPolicy() RetryPolicy {
+	return client.DefaultRetryPolicy()
+}
+
+// NewHTTPClient constructs an HTTP client with the given transport settings.
+func NewHTTPClient(opts TransportOptions) (*http.Client, error) {
+	return client.NewHTTPClient(opts)
+}
+
+// DefaultSecretPatterns are the variable names and file paths patterns which values are redacted in traces.
+var DefaultSecretPatterns = client.DefaultSecretPatterns
+
+// NewTracer constructs a Tracer writing JSON lines to the out writer.
+// The variables and files which names match any of the secret patterns (regular expressions) are redacted,
+// start from DefaultSecretPatterns to extend the defaults.
+func NewTracer(out io.Writer, secretPatterns []string) (*Tracer, error) {
+	return client.NewTracer(out, secretPatterns)
+}
+
+// IsNotFound returns true if the error is an API error for a missing object.
+func IsNotFound(err error) bool {
+	return client.IsNotFound(err)
+}
+
+// GetDeployedSet gets the Deployment Set of the last deployment in the environment.
+// Returns an empty set if the environment has not been deployed yet.
+func GetDeployedSet(ctx context.Context, c ClientV1, orgID, appID, envID string) (*Set, error) {
+	return client.GetDeployedSet(ctx, c, orgID, appID, envID)
+}
+
+// CloneEnvironment creates a new Environment with the envID, of the same type as the baseEnvID Environment,
+// and starting from the last Deployment of the baseEnvID Environment (if any).
+// The envName defaults to the envID if empty.
+func CloneEnvironment(ctx context.Context, c ClientV1, orgID, appID, baseEnvID, envID, envName string) (*Environment, error) {
+	return client.CloneEnvironment(ctx, c, orgID, appID, baseEnvID, envID, envName)
+}
+
+// WaitForDeployment polls the Deployment with the given deploymentID until it reaches a terminal state.
+// The onStatus callback (optional) is invoked every time the deployment status changes.
+// Use the context deadline to limit the waiting time.
+func WaitForDeployment(ctx context.Context, c ClientV1, orgID, appID, envID, deploymentID string, interval time.Duration, onStatus func(*Deployment)) (*Deployment, error) {
+	return client.WaitForDeployment(ctx, c, orgID, appID, envID, deploymentID, interval, onStatus)
+}
@@ -0,0 +1,69 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/score-spec/score-humanitec/pkg/humanitec/fake"
+)
+
+func TestClient(t *testing.T) {
+	var srv = fake.NewServer()
+	defer srv.Close()
+	srv.AddEnvironment("test-org", "test-app", Environment{ID: "development", Type: "development"})
+	srv.FailNext(http.MethodPost, "/orgs/test-org/apps/test-app/deltas", http.StatusServiceUnavailable, 1)
+
+	var trace bytes.Buffer
+	tracer, err := NewTracer(&trace, DefaultSecretPatterns)
+	assert.NoError(t, err)
+	client, err := NewClient(ClientOptions{
+		ApiUrl:      srv.URL,
+		Token:       srv.Token,
+		RetryPolicy: &RetryPolicy{MaxRetries: 1},
+		Tracer:      tracer,
+	})
+	assert.NoError(t, err)
+
+	var ctx = context.Background()
+	delta, err := client.CreateDelta(ctx, "test-org", "test-app", &CreateDeploymentDeltaRequest{
+		Metadata: DeltaMetadata{EnvID: "development"},
+		Modules: ModuleDeltas{
+			Add: map[string]map[string]interface{}{"backend": {"spec": map[string]interface{}{}}},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, trace.String(), `"status":503`)
+
+	deployment, err := client.StartDeployment(ctx, "test-org", "test-app", "development", false, &StartDeploymentRequest{DeltaID: delta.ID})
+	assert.NoError(t, err)
+	res, err := WaitForDeployment(ctx, client, "test-org", "test-app", "development", deployment.ID, time.Millisecond, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DeploymentStatusSucceeded, res.Status)
+
+	set, err := GetDeployedSet(ctx, client, "test-org", "test-app", "development")
+	assert.NoError(t, err)
+	assert.Contains(t, set.Modules, "backend")
+
+	_, err = client.GetEnvironment(ctx, "test-org", "test-app", "unknown")
+	assert.True(t, IsNotFound(err))
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+}
+
+func TestNewClient_errors(t *testing.T) {
+	_, err := NewClient(ClientOptions{Transport: TransportOptions{CAFile: "unknown.pem"}})
+	assert.ErrorContains(t, err, "unknown.pem")
+}
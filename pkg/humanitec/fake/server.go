@@ -0,0 +1,671 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+
+// Package fake provides an in-process fake of the Humanitec API for end-to-end tests.
+//
+// The server keeps the organizations, applications, environments, deployment deltas, deployment sets
+// and deployments in memory, and rejects requests that do not match the request types
+// of the 'pkg/humanitec' package. Point the client (or the '--api-url' flag) to the server URL:
+//
+//	var srv = fake.NewServer()
+//	defer srv.Close()
+//	srv.AddEnvironment("test-org", "test-app", humanitec.Environment{ID: "development", Type: "development"})
+//	client, err := humanitec.NewClient(humanitec.ClientOptions{ApiUrl: srv.URL, Token: srv.Token, HTTPClient: srv.Client()})
+package fake
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/score-spec/score-humanitec/internal/humanitec"
+	ht "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+const (
+	// DefaultToken is the API token accepted by the server unless configured otherwise.
+	DefaultToken = "fake-token"
+	// DefaultUser is the user reported as the creator of the objects.
+	DefaultUser = "fake-user@example.com"
+)
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Body   []byte
+}
+
+type fault struct {
+	method     string
+	path       string
+	statusCode int
+	count      int
+}
+
+type app struct {
+	envs        map[string]*ht.Environment
+	deltas      map[string]*ht.DeploymentDelta
+	sets        map[string]*ht.Set
+	deployments map[string][]*ht.Deployment
+}
+
+type org struct {
+	resourceTypes []ht.ResourceType
+	apps          map[string]*app
+}
+
+// Server is the fake Humanitec API server.
+type Server struct {
+	*httptest.Server
+
+	// Token is the expected bearer token. All tokens are accepted if empty.
+	Token string
+
+	// DeploymentStatus is the final status of the new deployments ('succeeded' by default).
+	DeploymentStatus string
+	// DeploymentPolls is the number of times a new deployment is reported 'in progress' before it completes.
+	DeploymentPolls int
+
+	mu       sync.Mutex
+	orgs     map[string]*org
+	faults   []*fault
+	requests []Request
+	polls    map[string]int
+	counter  int
+	clock    time.Time
+}
+
+// NewServer starts a new fake Humanitec API server. The server must be closed when done.
+func NewServer() *Server {
+	var s = &Server{
+		Token:            DefaultToken,
+		DeploymentStatus: ht.DeploymentStatusSucceeded,
+		orgs:             make(map[string]*org),
+		polls:            make(map[string]int),
+		clock:            time.Date(2020, 5, 22, 14, 0, 0, 0, time.UTC),
+	}
+
+	var mux = http.NewServeMux()
+	mux.HandleFunc("GET /orgs/{org}/resources/types", s.listResourceTypes)
+	mux.HandleFunc("POST /orgs/{org}/apps/{app}/envs", s.createEnvironment)
+	mux.HandleFunc("GET /orgs/{org}/apps/{app}/envs/{env}", s.getEnvironment)
+	mux.HandleFunc("DELETE /orgs/{org}/apps/{app}/envs/{env}", s.deleteEnvironment)
+	mux.HandleFunc("GET /orgs/{org}/apps/{app}/envs/{env}/deploys", s.listDeployments)
+	mux.HandleFunc("POST /orgs/{org}/apps/{app}/envs/{env}/deploys", s.startDeployment)
+	mux.HandleFunc("GET /orgs/{org}/apps/{app}/envs/{env}/deploys/{id}", s.getDeployment)
+	mux.HandleFunc("GET /orgs/{org}/apps/{app}/sets/{id}", s.getSet)
+	mux.HandleFunc("GET /orgs/{org}/apps/{app}/deltas", s.listDeltas)
+	mux.HandleFunc("POST /orgs/{org}/apps/{app}/deltas", s.createDelta)
+	mux.HandleFunc("GET /orgs/{org}/apps/{app}/deltas/{id}", s.getDelta)
+	mux.HandleFunc("PATCH /orgs/{org}/apps/{app}/deltas/{id}", s.updateDelta)
+	mux.HandleFunc("DELETE /orgs/{org}/apps/{app}/deltas/{id}", s.deleteDelta)
+	mux.HandleFunc("PUT /orgs/{org}/apps/{app}/deltas/{id}/metadata/archived", s.archiveDelta)
+
+	s.Server = httptest.NewServer(s.middleware(mux))
+	return s
+}
+
+// SetResourceTypes sets the resource types of the organization.
+func (s *Server) SetResourceTypes(orgID string, resourceTypes []ht.ResourceType) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.org(orgID).resourceTypes = resourceTypes
+}
+
+// AddApp adds an empty application to the organization.
+func (s *Server) AddApp(orgID, appID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.app(orgID, appID)
+}
+
+// AddEnvironment adds an environment to the application. The application is created if needed.
+func (s *Server) AddEnvironment(orgID, appID string, env ht.Environment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	env.CreatedAt = s.now()
+	env.CreatedBy = DefaultUser
+	s.app(orgID, appID).envs[env.ID] = &env
+}
+
+// FailNext makes the next count requests matching the method and path fail with the status code.
+// The failures are reported with a zero 'Retry-After' header, so that the clients retry immediately.
+func (s *Server) FailNext(method, path string, statusCode, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault{method: method, path: path, statusCode: statusCode, count: count})
+}
+
+// Requests returns all the requests received by the server.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request{}, s.requests...)
+}
+
+// Environment returns a copy of the environment, or nil if it does not exist.
+func (s *Server) Environment(orgID, appID, envID string) *ht.Environment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res *ht.Environment
+	if a := s.findApp(orgID, appID); a != nil && a.envs[envID] != nil {
+		copyJSON(a.envs[envID], &res)
+	}
+	return res
+}
+
+// Deltas returns copies of all the deployment deltas of the application, ordered by creation.
+func (s *Server) Deltas(orgID, appID string) []ht.DeploymentDelta {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res = []ht.DeploymentDelta{}
+	if a := s.findApp(orgID, appID); a != nil {
+		copyJSON(sortedDeltas(a.deltas), &res)
+	}
+	return res
+}
+
+// Deployments returns copies of all the deployments of the environment, ordered by creation.
+func (s *Server) Deployments(orgID, appID, envID string) []ht.Deployment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res = []ht.Deployment{}
+	if a := s.findApp(orgID, appID); a != nil {
+		copyJSON(a.deployments[envID], &res)
+	}
+	return res
+}
+
+// DeployedSet returns a copy of the deployment set of the last deployment in the environment,
+// or nil if the environment has not been deployed.
+func (s *Server) DeployedSet(orgID, appID, envID string) *ht.Set {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res *ht.Set
+	if a := s.findApp(orgID, appID); a != nil {
+		if env := a.envs[envID]; env != nil && env.LastDeploy != nil {
+			copyJSON(a.sets[env.LastDeploy.SetID], &res)
+		}
+	}
+	return res
+}
+
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Body: body})
+		for _, f := range s.faults {
+			if f.count > 0 && f.method == r.Method && f.path == r.URL.Path {
+				f.count--
+				s.mu.Unlock()
+				w.Header().Set("Retry-After", "0")
+				writeError(w, f.statusCode, "injected failure")
+				return
+			}
+		}
+		s.mu.Unlock()
+
+		if s.Token != "" && r.Header.Get("Authorization") != "Bearer "+s.Token {
+			writeError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) listResourceTypes(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, exists := s.orgs[r.PathValue("org")]
+	if !exists {
+		writeError(w, http.StatusNotFound, "organization not found")
+		return
+	}
+	var res = o.resourceTypes
+	if res == nil {
+		res = []ht.ResourceType{}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) createEnvironment(w http.ResponseWriter, r *http.Request) {
+	var req ht.CreateEnvironmentRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.ID == "" {
+		writeError(w, http.StatusUnprocessableEntity, "environment id is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.findApp(r.PathValue("org"), r.PathValue("app"))
+	if a == nil {
+		writeError(w, http.StatusNotFound, "application not found")
+		return
+	}
+	if _, exists := a.envs[req.ID]; exists {
+		writeError(w, http.StatusConflict, fmt.Sprintf("environment '%s' already exists", req.ID))
+		return
+	}
+
+	var env = &ht.Environment{ID: req.ID, Name: req.Name, Type: req.Type, CreatedBy: DefaultUser, CreatedAt: s.now()}
+	if req.FromDeployID != "" {
+		from := a.findDeployment(req.FromDeployID)
+		if from == nil {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("deployment '%s' not found", req.FromDeployID))
+			return
+		}
+		var deployment = &ht.Deployment{
+			ID:        s.newID(),
+			EnvID:     env.ID,
+			FromID:    from.ID,
+			SetID:     from.SetID,
+			Comment:   fmt.Sprintf("Cloned from deployment '%s'", from.ID),
+			Status:    ht.DeploymentStatusSucceeded,
+			CreatedBy: DefaultUser,
+			CreatedAt: s.now(),
+		}
+		deployment.StatusChangedAt = deployment.CreatedAt
+		a.deployments[env.ID] = append(a.deployments[env.ID], deployment)
+		env.LastDeploy = deployment
+	}
+	a.envs[env.ID] = env
+	writeJSON(w, http.StatusCreated, env)
+}
+
+func (s *Server) getEnvironment(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if env := s.findEnv(w, r); env != nil {
+		writeJSON(w, http.StatusOK, env)
+	}
+}
+
+func (s *Server) deleteEnvironment(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if env := s.findEnv(w, r); env != nil {
+		var a = s.findApp(r.PathValue("org"), r.PathValue("app"))
+		delete(a.envs, env.ID)
+		delete(a.deployments, env.ID)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) listDeployments(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if env := s.findEnv(w, r); env != nil {
+		var a = s.findApp(r.PathValue("org"), r.PathValue("app"))
+		var res = make([]*ht.Deployment, 0, len(a.deployments[env.ID]))
+		for i := len(a.deployments[env.ID]) - 1; i >= 0; i-- {
+			res = append(res, a.deployments[env.ID][i])
+		}
+		writeJSON(w, http.StatusOK, res)
+	}
+}
+
+func (s *Server) startDeployment(w http.ResponseWriter, r *http.Request) {
+	var req ht.StartDeploymentRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if (req.DeltaID == "") == (req.SetID == "") {
+		writeError(w, http.StatusUnprocessableEntity, "exactly one of delta_id or set_id is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	env := s.findEnv(w, r)
+	if env == nil {
+		return
+	}
+	if env.LastDeploy != nil && !env.LastDeploy.IsCompleted() {
+		writeError(w, http.StatusConflict, fmt.Sprintf("deployment '%s' is in progress", env.LastDeploy.ID))
+		return
+	}
+
+	var a = s.findApp(r.PathValue("org"), r.PathValue("app"))
+	var setID = req.SetID
+	if req.DeltaID != "" {
+		delta, exists := a.deltas[req.DeltaID]
+		if !exists {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("delta '%s' not found", req.DeltaID))
+			return
+		}
+		var base = &ht.Set{}
+		if env.LastDeploy != nil {
+			base = a.sets[env.LastDeploy.SetID]
+		}
+		set, err := humanitec.ApplyDelta(base, &ht.CreateDeploymentDeltaRequest{Modules: delta.Modules, Shared: delta.Shared})
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		setID = s.storeSet(a, set)
+	} else if _, exists := a.sets[setID]; !exists {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("set '%s' not found", setID))
+		return
+	}
+
+	var deployment = &ht.Deployment{
+		ID:        s.newID(),
+		EnvID:     env.ID,
+		DeltaID:   req.DeltaID,
+		SetID:     setID,
+		Comment:   req.Comment,
+		Status:    ht.DeploymentStatusInProgress,
+		CreatedBy: DefaultUser,
+		CreatedAt: s.now(),
+	}
+	if env.LastDeploy != nil {
+		deployment.FromID = env.LastDeploy.ID
+	}
+	deployment.StatusChangedAt = deployment.CreatedAt
+	if s.DeploymentPolls > 0 {
+		s.polls[deployment.ID] = s.DeploymentPolls
+	} else {
+		deployment.Status = s.DeploymentStatus
+	}
+	a.deployments[env.ID] = append(a.deployments[env.ID], deployment)
+	env.LastDeploy = deployment
+	writeJSON(w, http.StatusCreated, deployment)
+}
+
+func (s *Server) getDeployment(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	env := s.findEnv(w, r)
+	if env == nil {
+		return
+	}
+	var a = s.findApp(r.PathValue("org"), r.PathValue("app"))
+	for _, deployment := range a.deployments[env.ID] {
+		if deployment.ID == r.PathValue("id") {
+			if polls, pending := s.polls[deployment.ID]; pending {
+				if polls--; polls > 0 {
+					s.polls[deployment.ID] = polls
+				} else {
+					delete(s.polls, deployment.ID)
+					deployment.Status = s.DeploymentStatus
+					deployment.StatusChangedAt = s.now()
+				}
+			}
+			writeJSON(w, http.StatusOK, deployment)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "deployment not found")
+}
+
+func (s *Server) getSet(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.findApp(r.PathValue("org"), r.PathValue("app"))
+	if a == nil || a.sets[r.PathValue("id")] == nil {
+		writeError(w, http.StatusNotFound, "set not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, a.sets[r.PathValue("id")])
+}
+
+func (s *Server) listDeltas(w http.ResponseWriter, r *http.Request) {
+	archived, _ := strconv.ParseBool(r.URL.Query().Get("archived"))
+	var envID = r.URL.Query().Get("env")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.findApp(r.PathValue("org"), r.PathValue("app"))
+	if a == nil {
+		writeError(w, http.StatusNotFound, "application not found")
+		return
+	}
+	var res = []*ht.DeploymentDelta{}
+	for _, delta := range sortedDeltas(a.deltas) {
+		if delta.Metadata.Archived == archived && (envID == "" || delta.Metadata.EnvID == envID) {
+			res = append(res, delta)
+		}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) createDelta(w http.ResponseWriter, r *http.Request) {
+	var req ht.CreateDeploymentDeltaRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.findApp(r.PathValue("org"), r.PathValue("app"))
+	if a == nil {
+		writeError(w, http.StatusNotFound, "application not found")
+		return
+	}
+	if req.Metadata.EnvID != "" && a.envs[req.Metadata.EnvID] == nil {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("environment '%s' not found", req.Metadata.EnvID))
+		return
+	}
+
+	var now = s.now().Format(time.RFC3339)
+	var delta = &ht.DeploymentDelta{
+		ID:       s.newID(),
+		Metadata: req.Metadata,
+		Modules:  req.Modules,
+		Shared:   req.Shared,
+	}
+	delta.Metadata.Contributers = []string{DefaultUser}
+	delta.Metadata.CreatedBy = DefaultUser
+	delta.Metadata.CreatedAt = now
+	delta.Metadata.ModifiedAt = now
+	a.deltas[delta.ID] = delta
+	writeJSON(w, http.StatusOK, delta)
+}
+
+func (s *Server) getDelta(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if delta := s.findDelta(w, r); delta != nil {
+		writeJSON(w, http.StatusOK, delta)
+	}
+}
+
+func (s *Server) updateDelta(w http.ResponseWriter, r *http.Request) {
+	var req []*ht.UpdateDeploymentDeltaRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delta := s.findDelta(w, r)
+	if delta == nil {
+		return
+	}
+	for _, update := range req {
+		if update == nil {
+			continue
+		}
+		for name, mod := range update.Modules.Add {
+			if delta.Modules.Add == nil {
+				delta.Modules.Add = make(map[string]map[string]interface{})
+			}
+			delta.Modules.Add[name] = mod
+		}
+		delta.Modules.Remove = append(delta.Modules.Remove, update.Modules.Remove...)
+		for name, actions := range update.Modules.Update {
+			if delta.Modules.Update == nil {
+				delta.Modules.Update = make(map[string][]ht.UpdateAction)
+			}
+			delta.Modules.Update[name] = append(delta.Modules.Update[name], actions...)
+		}
+		delta.Shared = append(delta.Shared, update.Shared...)
+	}
+	delta.Metadata.ModifiedAt = s.now().Format(time.RFC3339)
+	writeJSON(w, http.StatusOK, delta)
+}
+
+func (s *Server) deleteDelta(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if delta := s.findDelta(w, r); delta != nil {
+		delete(s.findApp(r.PathValue("org"), r.PathValue("app")).deltas, delta.ID)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) archiveDelta(w http.ResponseWriter, r *http.Request) {
+	var archived bool
+	if !decodeJSON(w, r, &archived) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if delta := s.findDelta(w, r); delta != nil {
+		delta.Metadata.Archived = archived
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// org returns the organization, creating it if needed. Must be called with the lock held.
+func (s *Server) org(orgID string) *org {
+	if _, exists := s.orgs[orgID]; !exists {
+		s.orgs[orgID] = &org{apps: make(map[string]*app)}
+	}
+	return s.orgs[orgID]
+}
+
+// app returns the application, creating it if needed. Must be called with the lock held.
+func (s *Server) app(orgID, appID string) *app {
+	var o = s.org(orgID)
+	if _, exists := o.apps[appID]; !exists {
+		o.apps[appID] = &app{
+			envs:        make(map[string]*ht.Environment),
+			deltas:      make(map[string]*ht.DeploymentDelta),
+			sets:        make(map[string]*ht.Set),
+			deployments: make(map[string][]*ht.Deployment),
+		}
+	}
+	return o.apps[appID]
+}
+
+func (s *Server) findApp(orgID, appID string) *app {
+	if o, exists := s.orgs[orgID]; exists {
+		return o.apps[appID]
+	}
+	return nil
+}
+
+func (s *Server) findEnv(w http.ResponseWriter, r *http.Request) *ht.Environment {
+	if a := s.findApp(r.PathValue("org"), r.PathValue("app")); a != nil {
+		if env, exists := a.envs[r.PathValue("env")]; exists {
+			return env
+		}
+	}
+	writeError(w, http.StatusNotFound, "environment not found")
+	return nil
+}
+
+func (s *Server) findDelta(w http.ResponseWriter, r *http.Request) *ht.DeploymentDelta {
+	if a := s.findApp(r.PathValue("org"), r.PathValue("app")); a != nil {
+		if delta, exists := a.deltas[r.PathValue("id")]; exists {
+			return delta
+		}
+	}
+	writeError(w, http.StatusNotFound, "delta not found")
+	return nil
+}
+
+func (a *app) findDeployment(deploymentID string) *ht.Deployment {
+	for _, deployments := range a.deployments {
+		for _, deployment := range deployments {
+			if deployment.ID == deploymentID {
+				return deployment
+			}
+		}
+	}
+	return nil
+}
+
+// storeSet stores the deployment set under its content hash, the same way Humanitec identifies sets.
+func (s *Server) storeSet(a *app, set *ht.Set) string {
+	set.ID = ""
+	raw, _ := json.Marshal(set)
+	var hash = sha1.Sum(raw)
+	set.ID = hex.EncodeToString(hash[:])
+	a.sets[set.ID] = set
+	return set.ID
+}
+
+// newID returns a new unique object ID.
+func (s *Server) newID() string {
+	s.counter++
+	return fmt.Sprintf("%032x", s.counter)
+}
+
+// now returns the fake clock time, advancing it by a second each call, so that all the objects are ordered.
+func (s *Server) now() time.Time {
+	s.clock = s.clock.Add(time.Second)
+	return s.clock
+}
+
+func sortedDeltas(deltas map[string]*ht.DeploymentDelta) []*ht.DeploymentDelta {
+	var res = make([]*ht.DeploymentDelta, 0, len(deltas))
+	for _, delta := range deltas {
+		res = append(res, delta)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+// decodeJSON decodes the request body into the request type, rejecting unknown fields.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	var dec = json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %s", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, val interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(val)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, map[string]interface{}{
+		"error":   fmt.Sprintf("API-%d", statusCode),
+		"message": message,
+	})
+}
+
+func copyJSON(src, dst interface{}) {
+	raw, _ := json.Marshal(src)
+	json.Unmarshal(raw, dst)
+}
@@ -0,0 +1,157 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package fake
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/score-spec/score-humanitec/internal/humanitec_go/client"
+	ht "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+const (
+	orgID = "test-org"
+	appID = "test-app"
+	envID = "development"
+)
+
+func newTestClient(t *testing.T, srv *Server) client.Client {
+	c, err := client.NewClient(srv.URL, srv.Token, srv.Client(), client.WithRetryPolicy(client.RetryPolicy{MaxRetries: 3}))
+	assert.NoError(t, err)
+	return c
+}
+
+func TestServer_deploy(t *testing.T) {
+	var srv = NewServer()
+	defer srv.Close()
+	srv.AddEnvironment(orgID, appID, ht.Environment{ID: envID, Type: "development"})
+	var c = newTestClient(t, srv)
+	var ctx = context.Background()
+
+	// Create and update a delta
+	delta, err := c.CreateDelta(ctx, orgID, appID, &ht.CreateDeploymentDeltaRequest{
+		Metadata: ht.DeltaMetadata{EnvID: envID, Name: "Test delta"},
+		Modules: ht.ModuleDeltas{
+			Add: map[string]map[string]interface{}{"web": {"profile": "humanitec/default-module"}},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{DefaultUser}, delta.Metadata.Contributers)
+
+	delta, err = c.UpdateDelta(ctx, orgID, appID, delta.ID, []*ht.UpdateDeploymentDeltaRequest{
+		{Shared: []ht.UpdateAction{{Operation: "add", Path: "/dns", Value: map[string]interface{}{"type": "dns"}}}},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, delta.Shared, 1)
+
+	// Deploy the delta
+	deployment, err := c.StartDeployment(ctx, orgID, appID, envID, false, &ht.StartDeploymentRequest{DeltaID: delta.ID, Comment: "Test"})
+	assert.NoError(t, err)
+	assert.Equal(t, ht.DeploymentStatusSucceeded, deployment.Status)
+
+	set, err := client.GetDeployedSet(ctx, c, orgID, appID, envID)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]interface{}{"web": {"profile": "humanitec/default-module"}}, set.Modules)
+	assert.Equal(t, map[string]interface{}{"dns": map[string]interface{}{"type": "dns"}}, set.Shared)
+	assert.Equal(t, set, srv.DeployedSet(orgID, appID, envID))
+
+	// Archive the delta
+	assert.NoError(t, c.ArchiveDelta(ctx, orgID, appID, delta.ID))
+	deltas, err := c.ListDeltas(ctx, orgID, appID, envID, false)
+	assert.NoError(t, err)
+	assert.Empty(t, deltas)
+	deltas, err = c.ListDeltas(ctx, orgID, appID, envID, true)
+	assert.NoError(t, err)
+	assert.Len(t, deltas, 1)
+
+	// Redeploy the set into a cloned environment
+	env, err := client.CloneEnvironment(ctx, c, orgID, appID, envID, "pr-1", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "development", env.Type)
+	assert.Equal(t, set.ID, env.LastDeploy.SetID)
+
+	deployments, err := c.ListDeployments(ctx, orgID, appID, envID)
+	assert.NoError(t, err)
+	assert.Len(t, deployments, 1)
+	assert.NoError(t, c.DeleteEnvironment(ctx, orgID, appID, "pr-1"))
+	assert.Nil(t, srv.Environment(orgID, appID, "pr-1"))
+}
+
+func TestServer_deploymentProgress(t *testing.T) {
+	var srv = NewServer()
+	defer srv.Close()
+	srv.AddEnvironment(orgID, appID, ht.Environment{ID: envID, Type: "development"})
+	srv.DeploymentPolls = 2
+	srv.DeploymentStatus = ht.DeploymentStatusFailed
+	var c = newTestClient(t, srv)
+	var ctx = context.Background()
+
+	delta, err := c.CreateDelta(ctx, orgID, appID, &ht.CreateDeploymentDeltaRequest{Metadata: ht.DeltaMetadata{EnvID: envID}})
+	assert.NoError(t, err)
+	deployment, err := c.StartDeployment(ctx, orgID, appID, envID, false, &ht.StartDeploymentRequest{DeltaID: delta.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, ht.DeploymentStatusInProgress, deployment.Status)
+
+	// Deployments in progress conflict
+	_, err = c.StartDeployment(ctx, orgID, appID, envID, false, &ht.StartDeploymentRequest{DeltaID: delta.ID})
+	var apiErr *client.APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusConflict, apiErr.StatusCode)
+
+	res, err := client.WaitForDeployment(ctx, c, orgID, appID, envID, deployment.ID, time.Millisecond, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ht.DeploymentStatusFailed, res.Status)
+}
+
+func TestServer_faults(t *testing.T) {
+	var srv = NewServer()
+	defer srv.Close()
+	srv.SetResourceTypes(orgID, []ht.ResourceType{{Type: "postgres", Name: "PostgreSQL"}})
+	srv.FailNext(http.MethodGet, "/orgs/test-org/resources/types", http.StatusServiceUnavailable, 2)
+	var c = newTestClient(t, srv)
+
+	res, err := c.ListResourceTypes(context.Background(), orgID)
+	assert.NoError(t, err)
+	assert.Equal(t, []ht.ResourceType{{Type: "postgres", Name: "PostgreSQL"}}, res)
+	assert.Len(t, srv.Requests(), 3)
+}
+
+func TestServer_errors(t *testing.T) {
+	var srv = NewServer()
+	defer srv.Close()
+	srv.AddApp(orgID, appID)
+	var c = newTestClient(t, srv)
+	var ctx = context.Background()
+
+	// Unknown environment
+	_, err := c.GetEnvironment(ctx, orgID, appID, "unknown")
+	assert.True(t, client.IsNotFound(err))
+
+	// Unknown environment in the delta
+	_, err = c.CreateDelta(ctx, orgID, appID, &ht.CreateDeploymentDeltaRequest{Metadata: ht.DeltaMetadata{EnvID: "unknown"}})
+	assert.ErrorContains(t, err, "unexpected response status 422")
+
+	// Invalid request shape
+	resp, err := srv.Client().Post(srv.URL+"/orgs/test-org/apps/test-app/deltas", "application/json", bytes.NewBufferString(`{"unknown": true}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/orgs/test-org/apps/test-app/deltas", bytes.NewBufferString(`{"unknown": true}`))
+	req.Header.Set("Authorization", "Bearer "+DefaultToken)
+	resp, err = srv.Client().Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
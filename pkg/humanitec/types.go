@@ -0,0 +1,39 @@
+/*
+Apache Score
+Copyright 2020 The Apache Software Foundation
+
+This product includes software developed at
+The Apache Software Foundation (http://www.apache.org/).
+*/
+package humanitec
+
+import (
+	types "github.com/score-spec/score-humanitec/internal/humanitec_go/types"
+)
+
+// Humanitec API types.
+type (
+	CreateDeploymentDeltaRequest = types.CreateDeploymentDeltaRequest
+	UpdateDeploymentDeltaRequest = types.UpdateDeploymentDeltaRequest
+	DeploymentDelta              = types.DeploymentDelta
+	DeltaMetadata                = types.DeltaMetadata
+	ModuleDeltas                 = types.ModuleDeltas
+	UpdateAction                 = types.UpdateAction
+
+	StartDeploymentRequest = types.StartDeploymentRequest
+	Deployment             = types.Deployment
+
+	CreateEnvironmentRequest = types.CreateEnvironmentRequest
+	Environment              = types.Environment
+
+	ResourceType = types.ResourceType
+	Set          = types.Set
+)
+
+// Deployment statuses.
+const (
+	DeploymentStatusPending    = types.DeploymentStatusPending
+	DeploymentStatusInProgress = types.DeploymentStatusInProgress
+	DeploymentStatusSucceeded  = types.DeploymentStatusSucceeded
+	DeploymentStatusFailed     = types.DeploymentStatusFailed
+)